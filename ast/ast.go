@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/matt-snider/monkey/token"
 )
@@ -64,6 +65,24 @@ func (id *Identifier) String() string {
 	return id.Value
 }
 
+/**
+ * TypeName
+ */
+
+// TypeName is the type in an annotation such as `let x: int = 5`
+type TypeName struct {
+	Token token.Token
+	Value string
+}
+
+func (tn *TypeName) TokenLiteral() string {
+	return tn.Token.Literal
+}
+
+func (tn *TypeName) String() string {
+	return tn.Value
+}
+
 /**
  * IntegerLiteral
  */
@@ -83,6 +102,30 @@ func (il *IntegerLiteral) String() string {
 	return il.TokenLiteral()
 }
 
+/**
+ * UnparsedExpression
+ */
+
+// UnparsedExpression holds the tokens of an expression the parser
+// can't parse yet, so that they are not lost
+type UnparsedExpression struct {
+	Tokens []token.Token
+}
+
+func (ue *UnparsedExpression) expressionNode() {}
+
+func (ue *UnparsedExpression) TokenLiteral() string {
+	return ue.Tokens[0].Literal
+}
+
+func (ue *UnparsedExpression) String() string {
+	literals := make([]string, len(ue.Tokens))
+	for i, tok := range ue.Tokens {
+		literals[i] = tok.Literal
+	}
+	return strings.Join(literals, " ")
+}
+
 /**
  * ExpressionStatement
  */
@@ -99,10 +142,11 @@ func (es *ExpressionStatement) TokenLiteral() string {
 }
 
 func (es *ExpressionStatement) String() string {
+	// The semicolon keeps consecutive statements apart when printed
 	if es.Expression != nil {
-		return es.Expression.String()
+		return es.Expression.String() + ";"
 	}
-	return ""
+	return ";"
 }
 
 /**
@@ -112,6 +156,8 @@ func (es *ExpressionStatement) String() string {
 type LetStatement struct {
 	Token token.Token
 	Name  *Identifier
+	// Optional, nil when the binding is not annotated
+	Type  *TypeName
 	Value Expression
 }
 
@@ -126,6 +172,10 @@ func (ls *LetStatement) String() string {
 	buf.WriteString(ls.TokenLiteral())
 	buf.WriteString(" ")
 	buf.WriteString(ls.Name.String())
+	if ls.Type != nil {
+		buf.WriteString(": ")
+		buf.WriteString(ls.Type.String())
+	}
 	buf.WriteString(" = ")
 	if ls.Value != nil {
 		buf.WriteString(ls.Value.String())
@@ -27,3 +27,28 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got %q", program.String())
 	}
 }
+
+func TestExpressionStatementString(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "a"},
+				Expression: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "a"},
+					Value: "a",
+				},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "b"},
+				Expression: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "b"},
+					Value: "b",
+				},
+			},
+		},
+	}
+
+	if program.String() != "a;b;" {
+		t.Errorf("program.String() wrong. got %q", program.String())
+	}
+}
@@ -0,0 +1,159 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+// Dump writes node as an indented tree, one node per line with
+// its type, position and value, e.g.
+//
+//	Program
+//	  Statements[0]: LetStatement 1:1
+//	    Name: Identifier 1:5 x
+//	    Value: IntegerLiteral 1:9 5
+func Dump(node Node, w io.Writer) error {
+	d := &dumper{w: w}
+	d.dump("", node, 0)
+	return d.err
+}
+
+type dumper struct {
+	w   io.Writer
+	err error
+}
+
+func (d *dumper) dump(field string, node Node, depth int) {
+	if d.err != nil {
+		return
+	}
+
+	label := "nil"
+	if !isNil(node) {
+		label = describe(node)
+	}
+	if field != "" {
+		label = field + ": " + label
+	}
+	_, d.err = fmt.Fprintf(d.w, "%s%s\n", strings.Repeat("  ", depth), label)
+
+	if !isNil(node) {
+		for _, child := range children(node) {
+			d.dump(child.name, child.node, depth+1)
+		}
+	}
+}
+
+// DOT writes node as a Graphviz digraph, with one vertex per node
+// and edges labelled by field name
+func DOT(node Node, w io.Writer) error {
+	g := &grapher{w: w}
+	g.printf("digraph AST {\n")
+	g.printf("  node [shape=box, fontname=\"monospace\"];\n")
+	if !isNil(node) {
+		g.vertex(node)
+	}
+	g.printf("}\n")
+	return g.err
+}
+
+type grapher struct {
+	w     io.Writer
+	err   error
+	count int
+}
+
+func (g *grapher) printf(format string, args ...interface{}) {
+	if g.err == nil {
+		_, g.err = fmt.Fprintf(g.w, format, args...)
+	}
+}
+
+// Write the vertex for node and its subtree, returning its ID
+func (g *grapher) vertex(node Node) string {
+	id := fmt.Sprintf("n%d", g.count)
+	g.count++
+	g.printf("  %s [label=%s];\n", id, strconv.Quote(describe(node)))
+
+	for _, child := range children(node) {
+		if isNil(child.node) {
+			continue
+		}
+		childID := g.vertex(child.node)
+		g.printf("  %s -> %s [label=%s];\n", id, childID, strconv.Quote(child.name))
+	}
+	return id
+}
+
+/**
+ * Helpers
+ */
+
+type field struct {
+	name string
+	node Node
+}
+
+func children(node Node) []field {
+	switch node := node.(type) {
+	case *Program:
+		var fields []field
+		for i, statement := range node.Statements {
+			fields = append(fields, field{fmt.Sprintf("Statements[%d]", i), statement})
+		}
+		return fields
+	case *ExpressionStatement:
+		return []field{{"Expression", node.Expression}}
+	case *LetStatement:
+		fields := []field{{"Name", node.Name}}
+		if node.Type != nil {
+			fields = append(fields, field{"Type", node.Type})
+		}
+		return append(fields, field{"Value", node.Value})
+	case *ReturnStatement:
+		return []field{{"Value", node.Value}}
+	}
+	return nil
+}
+
+// describe returns the type of the node followed by its
+// position and value, if it has them
+func describe(node Node) string {
+	description := reflect.TypeOf(node).Elem().Name()
+
+	switch node := node.(type) {
+	case *Identifier:
+		description += " " + position(node.Token) + " " + node.Value
+	case *TypeName:
+		description += " " + position(node.Token) + " " + node.Value
+	case *IntegerLiteral:
+		description += " " + position(node.Token) + " " + strconv.FormatInt(node.Value, 10)
+	case *UnparsedExpression:
+		description += " " + position(node.Tokens[0]) + " " + node.String()
+	case *ExpressionStatement:
+		description += " " + position(node.Token)
+	case *LetStatement:
+		description += " " + position(node.Token)
+	case *ReturnStatement:
+		description += " " + position(node.Token)
+	}
+	return description
+}
+
+func position(tok token.Token) string {
+	return fmt.Sprintf("%d:%d", tok.Line, tok.Column)
+}
+
+// Nodes are pointers, so a nil *Identifier stored in a Node is not == nil
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	value := reflect.ValueOf(node)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
@@ -0,0 +1,71 @@
+package ast
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+func testProgram() *Program {
+	return &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let", Line: 1, Column: 1},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x", Line: 1, Column: 5},
+					Value: "x",
+				},
+				Value: &IntegerLiteral{
+					Token: token.Token{Type: token.INT, Literal: "5", Line: 1, Column: 9},
+					Value: 5,
+				},
+			},
+			&ReturnStatement{
+				Token: token.Token{Type: token.RETURN, Literal: "return", Line: 2, Column: 1},
+			},
+		},
+	}
+}
+
+func TestDump(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Dump(testProgram(), &buf); err != nil {
+		t.Fatalf("Dump() returned an error: %v", err)
+	}
+
+	expected := `Program
+  Statements[0]: LetStatement 1:1
+    Name: Identifier 1:5 x
+    Value: IntegerLiteral 1:9 5
+  Statements[1]: ReturnStatement 2:1
+    Value: nil
+`
+	if buf.String() != expected {
+		t.Errorf("Dump() wrong.\nexpected=%q\ngot=%q", expected, buf.String())
+	}
+}
+
+func TestDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := DOT(testProgram(), &buf); err != nil {
+		t.Fatalf("DOT() returned an error: %v", err)
+	}
+
+	expected := `digraph AST {
+  node [shape=box, fontname="monospace"];
+  n0 [label="Program"];
+  n1 [label="LetStatement 1:1"];
+  n2 [label="Identifier 1:5 x"];
+  n1 -> n2 [label="Name"];
+  n3 [label="IntegerLiteral 1:9 5"];
+  n1 -> n3 [label="Value"];
+  n0 -> n1 [label="Statements[0]"];
+  n4 [label="ReturnStatement 2:1"];
+  n0 -> n4 [label="Statements[1]"];
+}
+`
+	if buf.String() != expected {
+		t.Errorf("DOT() wrong.\nexpected=%q\ngot=%q", expected, buf.String())
+	}
+}
@@ -0,0 +1,213 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+// Nodes are serialized as JSON objects with a "type" discriminator
+// naming the node, its "token" (which includes its position) and
+// one field per child, e.g.
+//
+//	{"type": "Identifier", "token": {...}, "value": "x"}
+
+// MarshalJSON serializes any node
+func MarshalJSON(node Node) ([]byte, error) {
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON deserializes a node produced by MarshalJSON
+func UnmarshalJSON(data []byte) (Node, error) {
+	var n jsonNode
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+
+	switch n.Type {
+	case "Program":
+		program := &Program{}
+		for _, raw := range n.Statements {
+			statement, err := unmarshalStatement(raw)
+			if err != nil {
+				return nil, err
+			}
+			program.Statements = append(program.Statements, statement)
+		}
+		return program, nil
+	case "Identifier":
+		identifier := &Identifier{Token: n.Token}
+		if err := json.Unmarshal(n.Value, &identifier.Value); err != nil {
+			return nil, err
+		}
+		return identifier, nil
+	case "TypeName":
+		typeName := &TypeName{Token: n.Token}
+		if err := json.Unmarshal(n.Value, &typeName.Value); err != nil {
+			return nil, err
+		}
+		return typeName, nil
+	case "IntegerLiteral":
+		literal := &IntegerLiteral{Token: n.Token}
+		if err := json.Unmarshal(n.Value, &literal.Value); err != nil {
+			return nil, err
+		}
+		return literal, nil
+	case "UnparsedExpression":
+		if len(n.Tokens) == 0 {
+			return nil, fmt.Errorf("ast: unparsed expression without tokens")
+		}
+		return &UnparsedExpression{Tokens: n.Tokens}, nil
+	case "ExpressionStatement":
+		expression, err := unmarshalExpression(n.Expression)
+		if err != nil {
+			return nil, err
+		}
+		return &ExpressionStatement{Token: n.Token, Expression: expression}, nil
+	case "LetStatement":
+		name, err := unmarshalExpression(n.Name)
+		if err != nil {
+			return nil, err
+		}
+		identifier, ok := name.(*Identifier)
+		if name != nil && !ok {
+			return nil, fmt.Errorf("ast: let statement name must be an Identifier, got %T", name)
+		}
+		var typeName *TypeName
+		if len(n.Annotation) != 0 && !bytes.Equal(n.Annotation, []byte("null")) {
+			node, err := UnmarshalJSON(n.Annotation)
+			if err != nil {
+				return nil, err
+			}
+			if typeName, ok = node.(*TypeName); !ok {
+				return nil, fmt.Errorf("ast: let statement annotation must be a TypeName, got %T", node)
+			}
+		}
+		value, err := unmarshalExpression(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &LetStatement{Token: n.Token, Name: identifier, Type: typeName, Value: value}, nil
+	case "ReturnStatement":
+		value, err := unmarshalExpression(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &ReturnStatement{Token: n.Token, Value: value}, nil
+	}
+	return nil, fmt.Errorf("ast: unknown node type %q", n.Type)
+}
+
+// The union of the fields of every node
+type jsonNode struct {
+	Type       string            `json:"type"`
+	Token      token.Token       `json:"token"`
+	Name       json.RawMessage   `json:"name,omitempty"`
+	Annotation json.RawMessage   `json:"annotation,omitempty"`
+	Value      json.RawMessage   `json:"value,omitempty"`
+	Expression json.RawMessage   `json:"expression,omitempty"`
+	Statements []json.RawMessage `json:"statements,omitempty"`
+	Tokens     []token.Token     `json:"tokens,omitempty"`
+}
+
+func unmarshalStatement(data json.RawMessage) (Statement, error) {
+	node, err := UnmarshalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	statement, ok := node.(Statement)
+	if !ok {
+		return nil, fmt.Errorf("ast: expected a statement, got %T", node)
+	}
+	return statement, nil
+}
+
+// Missing and null expressions decode to nil
+func unmarshalExpression(data json.RawMessage) (Expression, error) {
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	node, err := UnmarshalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	expression, ok := node.(Expression)
+	if !ok {
+		return nil, fmt.Errorf("ast: expected an expression, got %T", node)
+	}
+	return expression, nil
+}
+
+/**
+ * Marshaling
+ */
+
+func (p *Program) MarshalJSON() ([]byte, error) {
+	statements := p.Statements
+	if statements == nil {
+		statements = []Statement{}
+	}
+	return json.Marshal(struct {
+		Type       string      `json:"type"`
+		Statements []Statement `json:"statements"`
+	}{"Program", statements})
+}
+
+func (id *Identifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string      `json:"type"`
+		Token token.Token `json:"token"`
+		Value string      `json:"value"`
+	}{"Identifier", id.Token, id.Value})
+}
+
+func (tn *TypeName) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string      `json:"type"`
+		Token token.Token `json:"token"`
+		Value string      `json:"value"`
+	}{"TypeName", tn.Token, tn.Value})
+}
+
+func (il *IntegerLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string      `json:"type"`
+		Token token.Token `json:"token"`
+		Value int64       `json:"value"`
+	}{"IntegerLiteral", il.Token, il.Value})
+}
+
+func (ue *UnparsedExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type   string        `json:"type"`
+		Tokens []token.Token `json:"tokens"`
+	}{"UnparsedExpression", ue.Tokens})
+}
+
+func (es *ExpressionStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string      `json:"type"`
+		Token      token.Token `json:"token"`
+		Expression Expression  `json:"expression"`
+	}{"ExpressionStatement", es.Token, es.Expression})
+}
+
+func (ls *LetStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string      `json:"type"`
+		Token      token.Token `json:"token"`
+		Name       *Identifier `json:"name"`
+		Annotation *TypeName   `json:"annotation,omitempty"`
+		Value      Expression  `json:"value"`
+	}{"LetStatement", ls.Token, ls.Name, ls.Type, ls.Value})
+}
+
+func (rs *ReturnStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string      `json:"type"`
+		Token token.Token `json:"token"`
+		Value Expression  `json:"value"`
+	}{"ReturnStatement", rs.Token, rs.Value})
+}
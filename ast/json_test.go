@@ -0,0 +1,80 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+func TestJSON(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let", Line: 1, Column: 1},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x", Line: 1, Column: 5},
+					Value: "x",
+				},
+				Value: &IntegerLiteral{
+					Token: token.Token{Type: token.INT, Literal: "5", Line: 1, Column: 9},
+					Value: 5,
+				},
+			},
+			&ReturnStatement{
+				Token: token.Token{Type: token.RETURN, Literal: "return", Line: 2, Column: 1},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "x", Line: 3, Column: 1},
+				Expression: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x", Line: 3, Column: 1},
+					Value: "x",
+				},
+			},
+		},
+	}
+
+	data, err := MarshalJSON(program)
+	if err != nil {
+		t.Fatalf("MarshalJSON() returned an error: %v", err)
+	}
+
+	expected := `{"type":"Program","statements":[` +
+		`{"type":"LetStatement","token":{"type":"LET","literal":"let","line":1,"column":1},` +
+		`"name":{"type":"Identifier","token":{"type":"IDENT","literal":"x","line":1,"column":5},"value":"x"},` +
+		`"value":{"type":"IntegerLiteral","token":{"type":"INT","literal":"5","line":1,"column":9},"value":5}},` +
+		`{"type":"ReturnStatement","token":{"type":"RETURN","literal":"return","line":2,"column":1},"value":null},` +
+		`{"type":"ExpressionStatement","token":{"type":"IDENT","literal":"x","line":3,"column":1},` +
+		`"expression":{"type":"Identifier","token":{"type":"IDENT","literal":"x","line":3,"column":1},"value":"x"}}]}`
+	if string(data) != expected {
+		t.Errorf("MarshalJSON() wrong.\nexpected=%s\ngot=%s", expected, data)
+	}
+
+	node, err := UnmarshalJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(node, program) {
+		t.Errorf("UnmarshalJSON() did not round-trip, got %s", node)
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"type":"Unknown"}`, `ast: unknown node type "Unknown"`},
+		{`{"type":"Program","statements":[{"type":"Identifier","value":"x"}]}`,
+			`ast: expected a statement, got *ast.Identifier`},
+		{`{"type":"ReturnStatement","value":{"type":"Program"}}`,
+			`ast: expected an expression, got *ast.Program`},
+	}
+
+	for _, tt := range tests {
+		_, err := UnmarshalJSON([]byte(tt.input))
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("UnmarshalJSON(%s) should fail with %q, got %v", tt.input, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package ast
+
+import "github.com/matt-snider/monkey/token"
+
+// Inspect traverses the tree rooted at node in depth-first order,
+// calling f for each node. Children are skipped when f returns false.
+func Inspect(node Node, f func(Node) bool) {
+	if isNil(node) || !f(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child.node, f)
+	}
+}
+
+// TokenOf returns the main token stored in node, so that its
+// position can be updated, or nil for nodes without a token
+func TokenOf(node Node) *token.Token {
+	switch node := node.(type) {
+	case *UnparsedExpression:
+		return &node.Tokens[0]
+	case *Identifier:
+		return &node.Token
+	case *TypeName:
+		return &node.Token
+	case *IntegerLiteral:
+		return &node.Token
+	case *ExpressionStatement:
+		return &node.Token
+	case *LetStatement:
+		return &node.Token
+	case *ReturnStatement:
+		return &node.Token
+	}
+	return nil
+}
+
+// TokensOf returns every token stored in node, not including
+// the tokens of its children
+func TokensOf(node Node) []*token.Token {
+	if unparsed, ok := node.(*UnparsedExpression); ok {
+		tokens := make([]*token.Token, len(unparsed.Tokens))
+		for i := range unparsed.Tokens {
+			tokens[i] = &unparsed.Tokens[i]
+		}
+		return tokens
+	}
+	if tok := TokenOf(node); tok != nil {
+		return []*token.Token{tok}
+	}
+	return nil
+}
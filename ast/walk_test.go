@@ -0,0 +1,55 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+func TestInspect(t *testing.T) {
+	var visited []string
+	Inspect(testProgram(), func(node Node) bool {
+		visited = append(visited, describe(node))
+		_, isLet := node.(*LetStatement)
+		return !isLet
+	})
+
+	expected := "Program|LetStatement 1:1|ReturnStatement 2:1"
+	if strings.Join(visited, "|") != expected {
+		t.Errorf("Inspect() visited %q, expected %q", visited, expected)
+	}
+}
+
+func TestTokenOf(t *testing.T) {
+	program := testProgram()
+	let := program.Statements[0].(*LetStatement)
+
+	TokenOf(let.Name).Line = 10
+	if let.Name.Token.Line != 10 {
+		t.Errorf("TokenOf() should point into the node")
+	}
+	if TokenOf(program) != nil {
+		t.Errorf("programs have no token")
+	}
+}
+
+func TestTokensOf(t *testing.T) {
+	unparsed := &UnparsedExpression{Tokens: []token.Token{
+		{Type: token.IDENT, Literal: "a"},
+		{Type: token.PLUS, Literal: "+"},
+	}}
+	tokens := TokensOf(unparsed)
+	if len(tokens) != 2 || tokens[1] != &unparsed.Tokens[1] {
+		t.Errorf("TokensOf() should point to every token of an unparsed expression")
+	}
+
+	program := testProgram()
+	let := program.Statements[0].(*LetStatement)
+	if tokens := TokensOf(let); len(tokens) != 1 || tokens[0] != &let.Token {
+		t.Errorf("TokensOf() should return the token of other nodes")
+	}
+	if TokensOf(program) != nil {
+		t.Errorf("programs have no token")
+	}
+}
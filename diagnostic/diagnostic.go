@@ -0,0 +1,125 @@
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type Severity string
+
+const (
+	ERROR   Severity = "error"
+	WARNING Severity = "warning"
+)
+
+// Diagnostic is a problem in a source file, located by the
+// line and column (both starting at 1) of its first character
+type Diagnostic struct {
+	Severity Severity
+	File     string
+	Line     int
+	Column   int
+	Length   int
+	Message  string
+	Notes    []string
+	Hints    []string
+}
+
+/**
+ * Rendering
+ */
+
+// ANSI escape codes used in colored mode
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	red    = "\x1b[31m"
+	yellow = "\x1b[33m"
+	cyan   = "\x1b[36m"
+)
+
+// Renderer prints diagnostics along with the offending source line
+// and a caret underlining the problem, e.g.
+//
+//	test.mk:1:6: error: expected next token to be =, got ;
+//	  1 | let x;
+//	    |      ^
+//	    = hint: ...
+type Renderer struct {
+	Color bool
+}
+
+// NewRenderer returns a renderer that uses color when w is
+// a terminal, unless disabled by setting NO_COLOR
+func NewRenderer(w io.Writer) *Renderer {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return &Renderer{}
+	}
+	info, err := f.Stat()
+	return &Renderer{Color: err == nil && info.Mode()&os.ModeCharDevice != 0}
+}
+
+// Render writes d to w, taking the offending line from source.
+// The line is left out when source is empty, e.g. when unavailable.
+func (r *Renderer) Render(w io.Writer, source string, d Diagnostic) {
+	color := red
+	if d.Severity == WARNING {
+		color = yellow
+	}
+
+	location := fmt.Sprintf("%d:%d", d.Line, d.Column)
+	if d.File != "" {
+		location = d.File + ":" + location
+	}
+	fmt.Fprintf(w, "%s: %s: %s\n",
+		r.paint(bold, location), r.paint(bold+color, string(d.Severity)), r.paint(bold, d.Message))
+
+	lines := strings.Split(source, "\n")
+	if source == "" || d.Line < 1 || d.Line > len(lines) {
+		r.renderFootnotes(w, "  ", d)
+		return
+	}
+
+	line := strings.TrimRight(lines[d.Line-1], "\r")
+	number := fmt.Sprint(d.Line)
+	gutter := strings.Repeat(" ", len(number)+3)
+	fmt.Fprintf(w, "%s%s %s\n", r.paint(cyan, "  "+number), r.paint(cyan, " |"), line)
+	fmt.Fprintf(w, "%s%s %s\n", gutter, r.paint(cyan, "|"), r.paint(bold+color, underline(line, d.Column, d.Length)))
+	r.renderFootnotes(w, gutter, d)
+}
+
+func (r *Renderer) renderFootnotes(w io.Writer, gutter string, d Diagnostic) {
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s%s %s\n", gutter, r.paint(cyan, "= note:"), note)
+	}
+	for _, hint := range d.Hints {
+		fmt.Fprintf(w, "%s%s %s\n", gutter, r.paint(cyan, "= hint:"), hint)
+	}
+}
+
+func (r *Renderer) paint(style string, s string) string {
+	if !r.Color {
+		return s
+	}
+	return style + s + reset
+}
+
+// underline returns the carets marking a span of line, keeping tabs
+// in the indentation so the carets line up in the terminal
+func underline(line string, column int, length int) string {
+	var prefix strings.Builder
+	for i := 0; i < column-1; i++ {
+		if i < len(line) && line[i] == '\t' {
+			prefix.WriteByte('\t')
+		} else {
+			prefix.WriteByte(' ')
+		}
+	}
+	if length < 1 {
+		length = 1
+	}
+	return prefix.String() + strings.Repeat("^", length)
+}
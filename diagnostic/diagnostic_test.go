@@ -0,0 +1,74 @@
+package diagnostic
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		source     string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			"let x;\n",
+			Diagnostic{
+				Severity: ERROR, File: "test.mk", Line: 1, Column: 6, Length: 1,
+				Message: "expected next token to be =, got ;",
+			},
+			"test.mk:1:6: error: expected next token to be =, got ;\n" +
+				"  1 | let x;\n" +
+				"    |      ^\n",
+		},
+		{
+			"let a = 1;\n\tlet abc = 2;",
+			Diagnostic{
+				Severity: WARNING, File: "test.mk", Line: 2, Column: 6, Length: 3,
+				Message: "abc is declared but never used (unused)", Hints: []string{"rename it"},
+			},
+			"test.mk:2:6: warning: abc is declared but never used (unused)\n" +
+				"  2 | \tlet abc = 2;\n" +
+				"    | \t    ^^^\n" +
+				"    = hint: rename it\n",
+		},
+		{
+			"",
+			Diagnostic{Severity: ERROR, Line: 3, Column: 1, Message: "oops", Notes: []string{"a note"}},
+			"3:1: error: oops\n" +
+				"  = note: a note\n",
+		},
+		{
+			"",
+			Diagnostic{Severity: ERROR, File: "-", Line: 1, Column: 1, Message: "oops"},
+			"-:1:1: error: oops\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		(&Renderer{}).Render(&buf, tt.source, tt.diagnostic)
+		if buf.String() != tt.expected {
+			t.Errorf("Render() wrong.\nexpected=%q\ngot=%q", tt.expected, buf.String())
+		}
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	var buf bytes.Buffer
+	d := Diagnostic{Severity: WARNING, Line: 1, Column: 1, Length: 1, Message: "m"}
+	(&Renderer{Color: true}).Render(&buf, "x", d)
+
+	expected := "\x1b[1m1:1\x1b[0m: \x1b[1m\x1b[33mwarning\x1b[0m: \x1b[1mm\x1b[0m\n" +
+		"\x1b[36m  1\x1b[0m\x1b[36m |\x1b[0m x\n" +
+		"    \x1b[36m|\x1b[0m \x1b[1m\x1b[33m^\x1b[0m\n"
+	if buf.String() != expected {
+		t.Errorf("Render() wrong.\nexpected=%q\ngot=%q", expected, buf.String())
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	if NewRenderer(&bytes.Buffer{}).Color {
+		t.Errorf("only terminals should get colored output")
+	}
+}
@@ -1,24 +1,78 @@
 package lexer
 
-import "github.com/matt-snider/monkey/token"
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+// Size of the buffer used when lexing from a reader
+const bufferSize = 4096
 
 type Lexer struct {
-	input        string
-	position     int
-	readPosition int
-	ch           byte
+	input *bufio.Reader
+	err   error
+	eof   bool
+	ch    byte
+
+	// Line and column of ch
+	line   int
+	column int
+
+	// In lossless mode, whitespace and comments are kept as
+	// the trivia of the token that follows them
+	lossless bool
+	trivia   []byte
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewReader(strings.NewReader(input))
+}
+
+// NewReader returns a lexer that reads its input incrementally,
+// buffering at most a few kilobytes at a time
+func NewReader(r io.Reader) *Lexer {
+	return newLexer(r, 1, 1)
+}
+
+// NewAt returns a lexer for a fragment of a larger input that
+// starts at the given line and column
+func NewAt(input string, line int, column int) *Lexer {
+	return newLexer(strings.NewReader(input), line, column)
+}
+
+func newLexer(r io.Reader, line int, column int) *Lexer {
+	l := &Lexer{
+		input:  bufio.NewReaderSize(r, bufferSize),
+		line:   line,
+		column: column - 1,
+	}
 	l.readChar()
 	return l
 }
 
+// NewLossless returns a lexer in lossless mode, where concatenating
+// the Trivia and Literal of every token up to and including EOF
+// reproduces the input exactly
+func NewLossless(input string) *Lexer {
+	l := New(input)
+	l.lossless = true
+	return l
+}
+
+// Err returns the first error encountered while reading the input,
+// other than io.EOF. Lexing stops with an EOF token on error.
+func (l *Lexer) Err() error {
+	return l.err
+}
+
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
 	l.eatWhitespace()
+	line, column := l.line, l.column
 	switch l.ch {
 	case '+':
 		tok = simpleToken(token.PLUS, l.ch)
@@ -48,6 +102,8 @@ func (l *Lexer) NextToken() token.Token {
 		tok = simpleToken(token.GT, l.ch)
 	case ',':
 		tok = simpleToken(token.COMMA, l.ch)
+	case ':':
+		tok = simpleToken(token.COLON, l.ch)
 	case ';':
 		tok = simpleToken(token.SEMICOLON, l.ch)
 	case '(':
@@ -59,7 +115,11 @@ func (l *Lexer) NextToken() token.Token {
 	case '}':
 		tok = simpleToken(token.RBRACE, l.ch)
 	case 0:
-		tok = newToken(token.EOF, "")
+		if l.eof {
+			tok = newToken(token.EOF, "")
+		} else {
+			tok = simpleToken(token.ILLEGAL, l.ch)
+		}
 	default:
 		if isLetter(l.ch) {
 			literal := l.readIdentifier()
@@ -77,11 +137,16 @@ func (l *Lexer) NextToken() token.Token {
 	if !managesOwnPosition(tok.Type) {
 		l.readChar()
 	}
+	tok.Line, tok.Column = line, column
+	if l.lossless {
+		tok.Trivia = string(l.trivia)
+		l.trivia = l.trivia[:0]
+	}
 	return tok
 }
 
 func simpleToken(tokenType token.TokenType, ch byte) token.Token {
-	return newToken(tokenType, string(ch))
+	return newToken(tokenType, string([]byte{ch}))
 }
 
 func newToken(tokenType token.TokenType, lit string) token.Token {
@@ -89,47 +154,83 @@ func newToken(tokenType token.TokenType, lit string) token.Token {
 }
 
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
+	ch, err := l.input.ReadByte()
+	if err != nil {
+		l.setErr(err)
+		l.eof = true
+		ch = 0
 	}
-	l.position = l.readPosition
-	l.readPosition++
+	l.ch = ch
 }
 
 func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+	next, err := l.input.Peek(1)
+	if err != nil {
+		l.setErr(err)
 		return 0
-	} else {
-		return l.input[l.readPosition]
+	}
+	return next[0]
+}
+
+func (l *Lexer) setErr(err error) {
+	if err != io.EOF && l.err == nil {
+		l.err = err
 	}
 }
 
 func (l *Lexer) readIdentifier() string {
-	position := l.position
+	var literal []byte
 	for isLetter(l.ch) {
+		literal = append(literal, l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return string(literal)
 }
 
 func (l *Lexer) readNumber() string {
-	position := l.position
+	var literal []byte
 	for isNumber(l.ch) {
+		literal = append(literal, l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return string(literal)
 }
 
 func (l *Lexer) eatWhitespace() {
-	for isWhitespace(l.ch) {
-		l.readChar()
+	for {
+		if isWhitespace(l.ch) {
+			l.skipChar()
+		} else if l.ch == '/' && l.peekChar() == '/' {
+			l.eatComment()
+		} else {
+			return
+		}
+	}
+}
+
+// Comments run from // to the end of the line
+func (l *Lexer) eatComment() {
+	for l.ch != '\n' && !l.eof {
+		l.skipChar()
 	}
 }
 
+// Advance past a character that is not part of a token
+func (l *Lexer) skipChar() {
+	if l.lossless {
+		l.trivia = append(l.trivia, l.ch)
+	}
+	l.readChar()
+}
+
 func isWhitespace(ch byte) bool {
-	return ch == ' ' || ch == '\t' || ch == '\n'
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
 func isLetter(ch byte) bool {
@@ -1,7 +1,10 @@
 package lexer
 
 import (
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/matt-snider/monkey/token"
 )
@@ -26,6 +29,7 @@ func TestNextToken(t *testing.T) {
 
 		let x = 10 - 5 * 5 / 3;
 		return !false;
+		let y: int = 1;
 	`
 
 	tests := []struct {
@@ -139,6 +143,15 @@ func TestNextToken(t *testing.T) {
 		{token.FALSE, "false"},
 		{token.SEMICOLON, ";"},
 
+		// let y: int = 1;
+		{token.LET, "let"},
+		{token.IDENT, "y"},
+		{token.COLON, ":"},
+		{token.IDENT, "int"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+
 		{token.EOF, ""},
 	}
 
@@ -168,3 +181,229 @@ func TestIllegalToken(t *testing.T) {
 		t.Fatalf("TestIllegalToken - literal wrong. expected=~, got=%q", tok.Literal)
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x == 10\n"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"x", 2, 3},
+		{"==", 2, 5},
+		{"10", 2, 8},
+		{"", 3, 1},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("TestTokenPositions[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("TestTokenPositions[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestCRLF(t *testing.T) {
+	input := "let x = 5;\r\nx; // comment\r\n"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+	}{
+		{token.LET, 1, 1},
+		{token.IDENT, 1, 5},
+		{token.ASSIGN, 1, 7},
+		{token.INT, 1, 9},
+		{token.SEMICOLON, 1, 10},
+		{token.IDENT, 2, 1},
+		{token.SEMICOLON, 2, 2},
+		{token.EOF, 3, 1},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("TestCRLF[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("TestCRLF[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `
+		// a comment
+		let x = 10 / 2; // trailing comment
+		//
+	`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("TestComments[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("TestComments[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	inputs := []string{
+		"",
+		"let add = fn(x, y) { x + y; };\nadd(1, 2) != 3 // done",
+		strings.Repeat("let abcdefghij = 1234567890 == !x;\n", 1000),
+	}
+
+	for _, input := range inputs {
+		expected := New(input)
+		// Reading a byte at a time exercises every buffer boundary
+		actual := NewReader(iotest.OneByteReader(strings.NewReader(input)))
+
+		for i := 0; ; i++ {
+			want, got := expected.NextToken(), actual.NextToken()
+			if want != got {
+				t.Fatalf("TestNewReader[%d] - token wrong. expected=%+v, got=%+v",
+					i, want, got)
+			}
+			if want.Type == token.EOF {
+				break
+			}
+		}
+		if actual.Err() != nil {
+			t.Fatalf("TestNewReader - unexpected error %v", actual.Err())
+		}
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	input := io.MultiReader(strings.NewReader("let x"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	l := NewReader(input)
+
+	for _, expected := range []token.TokenType{token.LET, token.IDENT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != expected {
+			t.Fatalf("TestNewReaderError - tokentype wrong. expected=%q, got=%q",
+				expected, tok.Type)
+		}
+	}
+	if l.Err() != io.ErrUnexpectedEOF {
+		t.Fatalf("TestNewReaderError - expected %v, got %v", io.ErrUnexpectedEOF, l.Err())
+	}
+}
+
+func TestLossless(t *testing.T) {
+	inputs := []string{
+		"",
+		"   ",
+		"let x = 5;",
+		"\n\t let  add = fn(x, y) {\n  x + y; // sum\n};\n\n// trailing comment",
+		"let a\x00b = \"héllo\";\r\n",
+		"x //",
+	}
+
+	for _, input := range inputs {
+		l := NewLossless(input)
+		var output strings.Builder
+		for {
+			tok := l.NextToken()
+			output.WriteString(tok.Trivia)
+			output.WriteString(tok.Literal)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+
+		if output.String() != input {
+			t.Errorf("TestLossless - expected %q, got %q", input, output.String())
+		}
+	}
+}
+
+func TestNulByte(t *testing.T) {
+	l := New("a\x00b")
+	expected := []token.TokenType{token.IDENT, token.ILLEGAL, token.IDENT, token.EOF}
+	for i, tt := range expected {
+		if tok := l.NextToken(); tok.Type != tt {
+			t.Fatalf("TestNulByte[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+}
+
+func FuzzLexer(f *testing.F) {
+	f.Add("let add = fn(x, y) { x + y; };")
+	f.Add("if (a != b) { return !true; } // done")
+	f.Add("a\x00b ~ \r\n")
+	f.Fuzz(func(t *testing.T, input string) {
+		l := NewLossless(input)
+		var output strings.Builder
+		// Every token consumes at least one byte, so the lexer
+		// must reach EOF within len(input)+1 tokens
+		for i := 0; ; i++ {
+			if i > len(input) {
+				t.Fatalf("lexer did not reach EOF for %q", input)
+			}
+			tok := l.NextToken()
+			output.WriteString(tok.Trivia)
+			output.WriteString(tok.Literal)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+
+		if output.String() != input {
+			t.Fatalf("lossless lexing of %q produced %q", input, output.String())
+		}
+	})
+}
+
+func TestNewAt(t *testing.T) {
+	l := NewAt("x\n y", 3, 7)
+	expected := []struct {
+		line   int
+		column int
+	}{{3, 7}, {4, 2}, {4, 3}}
+
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Fatalf("TestNewAt[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package lexer
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+// Tokens returns an iterator over the tokens of src, excluding EOF
+func Tokens(src string) iter.Seq[token.Token] {
+	return func(yield func(token.Token) bool) {
+		l := New(src)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			if !yield(tok) {
+				return
+			}
+		}
+	}
+}
+
+// All returns every token of src, excluding EOF. If src contains
+// illegal characters, all tokens are still returned along with
+// an error describing the first one.
+func All(src string) ([]token.Token, error) {
+	var tokens []token.Token
+	var err error
+	for tok := range Tokens(src) {
+		if tok.Type == token.ILLEGAL && err == nil {
+			err = fmt.Errorf("%d:%d: illegal character %q", tok.Line, tok.Column, tok.Literal)
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, err
+}
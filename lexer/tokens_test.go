@@ -0,0 +1,47 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/matt-snider/monkey/token"
+)
+
+func TestTokens(t *testing.T) {
+	expected := []token.TokenType{token.LET, token.IDENT, token.ASSIGN, token.INT}
+
+	var actual []token.TokenType
+	for tok := range Tokens("let x = 5; x") {
+		if len(actual) == len(expected) {
+			break
+		}
+		actual = append(actual, tok.Type)
+	}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("TestTokens - expected %d tokens, got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("TestTokens[%d] - tokentype wrong. expected=%q, got=%q",
+				i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	tokens, err := All("let x = 5;")
+	if err != nil {
+		t.Fatalf("TestAll - unexpected error %v", err)
+	}
+	if len(tokens) != 5 {
+		t.Fatalf("TestAll - expected %d tokens, got %d", 5, len(tokens))
+	}
+
+	tokens, err = All("let x = ~;\n~")
+	if len(tokens) != 6 {
+		t.Fatalf("TestAll - expected %d tokens, got %d", 6, len(tokens))
+	}
+	if err == nil || err.Error() != `1:9: illegal character "~"` {
+		t.Fatalf("TestAll - wrong error, got %v", err)
+	}
+}
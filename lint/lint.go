@@ -0,0 +1,230 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/diagnostic"
+	"github.com/matt-snider/monkey/token"
+)
+
+// Rule IDs, used in diagnostics and in lint:ignore comments
+const (
+	UNDEFINED   = "undefined"
+	UNUSED      = "unused"
+	SHADOW      = "shadow"
+	UNREACHABLE = "unreachable"
+)
+
+type Diagnostic struct {
+	Line    int
+	Column  int
+	Length  int
+	Rule    string
+	Message string
+
+	// Optional suggestion on how to fix the problem
+	Hint string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", d.Line, d.Column, d.Message, d.Rule)
+}
+
+// ToDiagnostic converts d to a warning, naming its rule
+// in the message
+func (d Diagnostic) ToDiagnostic(file string) diagnostic.Diagnostic {
+	w := diagnostic.Diagnostic{
+		Severity: diagnostic.WARNING,
+		File:     file,
+		Line:     d.Line,
+		Column:   d.Column,
+		Length:   d.Length,
+		Message:  fmt.Sprintf("%s (%s)", d.Message, d.Rule),
+	}
+	if d.Hint != "" {
+		w.Hints = append(w.Hints, d.Hint)
+	}
+	return w
+}
+
+// Lint analyzes a parsed program and returns its diagnostics
+// ordered by position
+func Lint(program *ast.Program) []Diagnostic {
+	l := &linter{}
+	l.lintBlock(program.Statements, newScope(nil))
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i], l.diagnostics[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.diagnostics
+}
+
+type linter struct {
+	diagnostics []Diagnostic
+}
+
+func (l *linter) report(tok token.Token, rule string, format string, args ...interface{}) *Diagnostic {
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Length:  len(tok.Literal),
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+	return &l.diagnostics[len(l.diagnostics)-1]
+}
+
+/**
+ * Scopes
+ */
+
+type binding struct {
+	name *ast.Identifier
+	used bool
+}
+
+type scope struct {
+	parent   *scope
+	bindings map[string]*binding
+	order    []*binding
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, bindings: make(map[string]*binding)}
+}
+
+func (s *scope) lookup(name string) *binding {
+	for ; s != nil; s = s.parent {
+		if b, ok := s.bindings[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+func (l *linter) declare(s *scope, name *ast.Identifier) {
+	if previous := s.lookup(name.Value); previous != nil {
+		l.report(name.Token, SHADOW, "%s shadows the binding at %d:%d",
+			name.Value, previous.name.Token.Line, previous.name.Token.Column)
+	}
+	b := &binding{name: name}
+	s.bindings[name.Value] = b
+	s.order = append(s.order, b)
+}
+
+// Report unused bindings once the scope has been fully walked
+func (l *linter) closeScope(s *scope) {
+	for _, b := range s.order {
+		if !b.used && !strings.HasPrefix(b.name.Value, "_") {
+			d := l.report(b.name.Token, UNUSED, "%s is declared but never used", b.name.Value)
+			d.Hint = fmt.Sprintf("rename it to _%s if this is intentional", b.name.Value)
+		}
+	}
+}
+
+/**
+ * Walking
+ */
+
+func (l *linter) lintBlock(statements []ast.Statement, s *scope) {
+	returned := false
+	for _, statement := range statements {
+		if returned {
+			l.report(statementToken(statement), UNREACHABLE, "unreachable code after return")
+			returned = false
+		}
+
+		switch statement := statement.(type) {
+		case *ast.LetStatement:
+			// The value is evaluated before the name is bound
+			l.lintExpression(statement.Value, s)
+			l.declare(s, statement.Name)
+		case *ast.ReturnStatement:
+			l.lintExpression(statement.Value, s)
+			returned = true
+		case *ast.ExpressionStatement:
+			l.lintExpression(statement.Expression, s)
+		}
+	}
+	l.closeScope(s)
+}
+
+func (l *linter) lintExpression(expression ast.Expression, s *scope) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		if b := s.lookup(expression.Value); b != nil {
+			b.used = true
+		} else {
+			l.report(expression.Token, UNDEFINED, "undefined: %s", expression.Value)
+		}
+	case *ast.UnparsedExpression:
+		// Identifiers may refer to bindings, but may also be parameters
+		// or bindings inside it, so undefined ones are not reported
+		for _, tok := range expression.Tokens {
+			if b := s.lookup(tok.Literal); b != nil && tok.Type == token.IDENT {
+				b.used = true
+			}
+		}
+	}
+}
+
+func statementToken(statement ast.Statement) token.Token {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		return statement.Token
+	case *ast.ReturnStatement:
+		return statement.Token
+	case *ast.ExpressionStatement:
+		return statement.Token
+	}
+	return token.Token{}
+}
+
+/**
+ * Suppression
+ */
+
+var ignoreDirective = regexp.MustCompile(`//\s*lint:ignore\s+([\w,-]+)`)
+
+// Suppress removes diagnostics silenced by a comment of the form
+//
+//	// lint:ignore rule[,rule...]
+//
+// A directive at the end of a line applies to that line, while a
+// directive on a line of its own applies to the line after it.
+func Suppress(diagnostics []Diagnostic, input string) []Diagnostic {
+	ignored := make(map[int]map[string]bool)
+	for i, line := range strings.Split(input, "\n") {
+		match := ignoreDirective.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+
+		n := i + 1
+		if strings.TrimSpace(line[:match[0]]) == "" {
+			n++
+		}
+		if ignored[n] == nil {
+			ignored[n] = make(map[string]bool)
+		}
+		for _, rule := range strings.Split(line[match[2]:match[3]], ",") {
+			ignored[n][rule] = true
+		}
+	}
+
+	var kept []Diagnostic
+	for _, d := range diagnostics {
+		if !ignored[d.Line][d.Rule] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
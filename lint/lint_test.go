@@ -0,0 +1,95 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/matt-snider/monkey/diagnostic"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/parser"
+)
+
+func lint(t *testing.T, input string) []Diagnostic {
+	p := parser.New(lexer.New(input))
+	program := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %v", p.Errors())
+	}
+	return Suppress(Lint(program), input)
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 5; x;", nil},
+		{"y;", []string{"1:1: undefined: y (undefined)"}},
+		{"let x = x;", []string{
+			"1:5: x is declared but never used (unused)",
+			"1:9: undefined: x (undefined)",
+		}},
+		{"let x = 5;\nlet x = 6;\nx;", []string{
+			"1:5: x is declared but never used (unused)",
+			"2:5: x shadows the binding at 1:5 (shadow)",
+		}},
+		{"let _x = 5;", nil},
+		// Values the parser can't parse yet still use bindings
+		{"let a = 1; let b = 2 + a; b;", nil},
+		{"let c = 3 + y;", []string{
+			"1:5: c is declared but never used (unused)",
+		}},
+		{"let x = 5;\nreturn x;\nx;\n5;", []string{
+			"3:1: unreachable code after return (unreachable)",
+		}},
+	}
+
+	for _, tt := range tests {
+		diagnostics := lint(t, tt.input)
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("%q: expected %d diagnostics, got %v",
+				tt.input, len(tt.expected), diagnostics)
+			continue
+		}
+		for i, expectation := range tt.expected {
+			if diagnostics[i].String() != expectation {
+				t.Errorf("%q: diagnostic %d should be %q, got %q",
+					tt.input, i, expectation, diagnostics[i].String())
+			}
+		}
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	input := `
+		// lint:ignore unused
+		let a = 5;
+		let b = 6; // lint:ignore unused,shadow
+		let c = 7; // lint:ignore shadow
+	`
+
+	diagnostics := lint(t, input)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diagnostics)
+	}
+	if diagnostics[0].Rule != UNUSED || diagnostics[0].Line != 5 {
+		t.Errorf("expected unused c on line 5, got %s", diagnostics[0])
+	}
+}
+
+func TestToDiagnostic(t *testing.T) {
+	d := Diagnostic{
+		Line: 2, Column: 6, Length: 3, Rule: UNUSED,
+		Message: "abc is declared but never used", Hint: "rename it",
+	}.ToDiagnostic("test.mk")
+
+	if d.Severity != diagnostic.WARNING || d.File != "test.mk" || d.Line != 2 ||
+		d.Column != 6 || d.Length != 3 {
+		t.Errorf("wrong position or severity %+v", d)
+	}
+	if d.Message != "abc is declared but never used (unused)" {
+		t.Errorf("wrong message %q", d.Message)
+	}
+	if len(d.Hints) != 1 || d.Hints[0] != "rename it" {
+		t.Errorf("wrong hints %v", d.Hints)
+	}
+}
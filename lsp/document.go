@@ -0,0 +1,276 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/lint"
+	"github.com/matt-snider/monkey/parser"
+	"github.com/matt-snider/monkey/token"
+)
+
+// document is an open text document along with everything
+// the server knows about it
+type document struct {
+	uri    string
+	text   string
+	lines  []string
+	tokens []token.Token
+
+	program *ast.Program
+	errors  []parser.Error
+
+	// Let statements in source order
+	lets []*ast.LetStatement
+
+	// Maps the position of every resolved identifier (including the
+	// names in let statements) to the let name that defines it
+	definitions map[position]*ast.Identifier
+
+	// Maps a let name to every identifier referring to it,
+	// starting with the let name itself
+	references map[*ast.Identifier][]*ast.Identifier
+}
+
+type position struct {
+	line   int
+	column int
+}
+
+func newDocument(uri string, text string) *document {
+	d := &document{
+		uri:         uri,
+		text:        text,
+		lines:       strings.Split(text, "\n"),
+		definitions: make(map[position]*ast.Identifier),
+		references:  make(map[*ast.Identifier][]*ast.Identifier),
+	}
+
+	d.tokens, _ = lexer.All(text)
+
+	p := parser.New(lexer.New(text))
+	d.program = p.Parse()
+	d.errors = p.DetailedErrors()
+	d.resolve()
+	return d
+}
+
+// Bind every identifier to the closest preceding let statement
+func (d *document) resolve() {
+	scope := make(map[string]*ast.Identifier)
+
+	use := func(expression ast.Expression) {
+		ident, ok := expression.(*ast.Identifier)
+		if !ok {
+			return
+		}
+		if definition, ok := scope[ident.Value]; ok {
+			d.definitions[positionOf(ident.Token)] = definition
+			d.references[definition] = append(d.references[definition], ident)
+		}
+	}
+
+	for _, statement := range d.program.Statements {
+		switch statement := statement.(type) {
+		case *ast.LetStatement:
+			use(statement.Value)
+			d.lets = append(d.lets, statement)
+			d.definitions[positionOf(statement.Name.Token)] = statement.Name
+			d.references[statement.Name] = []*ast.Identifier{statement.Name}
+			scope[statement.Name.Value] = statement.Name
+		case *ast.ReturnStatement:
+			use(statement.Value)
+		case *ast.ExpressionStatement:
+			use(statement.Expression)
+		}
+	}
+}
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, e := range d.errors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.spanAt(e.Line, e.Column, e.Length),
+			Severity: SEVERITY_ERROR,
+			Source:   "monkey",
+			Message:  e.Message,
+		})
+	}
+
+	// Lint results are unreliable for programs that failed to parse
+	if len(d.errors) != 0 {
+		return diagnostics
+	}
+	for _, l := range lint.Suppress(lint.Lint(d.program), d.text) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.spanAt(l.Line, l.Column, l.Length),
+			Severity: SEVERITY_WARNING,
+			Code:     l.Rule,
+			Source:   "monkey vet",
+			Message:  l.Message,
+		})
+	}
+	return diagnostics
+}
+
+// tokenAt returns the token under (or directly before) the cursor
+func (d *document) tokenAt(pos Position) (token.Token, bool) {
+	for _, tok := range d.tokens {
+		r := d.rangeOf(tok)
+		if r.Start.Line == pos.Line &&
+			r.Start.Character <= pos.Character && pos.Character <= r.End.Character {
+			return tok, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// definitionAt returns the let name bound to the identifier at pos
+func (d *document) definitionAt(pos Position) *ast.Identifier {
+	tok, ok := d.tokenAt(pos)
+	if !ok || tok.Type != token.IDENT {
+		return nil
+	}
+	return d.definitions[positionOf(tok)]
+}
+
+func (d *document) hover(pos Position) *Hover {
+	tok, _ := d.tokenAt(pos)
+	definition := d.definitionAt(pos)
+	if definition == nil {
+		return nil
+	}
+
+	uses := len(d.references[definition]) - 1
+	return &Hover{
+		Contents: MarkupContent{
+			Kind: "markdown",
+			Value: fmt.Sprintf("```monkey\nlet %s\n```\nDefined on line %d, used %d time(s)",
+				definition.Value, definition.Token.Line, uses),
+		},
+		Range: d.rangeOf(tok),
+	}
+}
+
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, let := range d.lets {
+		symbols = append(symbols, DocumentSymbol{
+			Name:           let.Name.Value,
+			Kind:           SYMBOL_KIND_VARIABLE,
+			Range:          Range{Start: d.rangeOf(let.Token).Start, End: d.rangeOf(let.Name.Token).End},
+			SelectionRange: d.rangeOf(let.Name.Token),
+		})
+	}
+	return symbols
+}
+
+/**
+ * Semantic tokens
+ */
+
+var semanticTokenTypes = []string{"keyword", "variable", "number", "operator"}
+
+var semanticTokenModifiers = []string{"declaration"}
+
+func (d *document) semanticTokens() SemanticTokens {
+	data := []int{}
+	previous := Position{}
+	for _, tok := range d.tokens {
+		tokenType := semanticTokenType(tok.Type)
+		if tokenType < 0 {
+			continue
+		}
+
+		modifiers := 0
+		if definition := d.definitions[positionOf(tok)]; definition != nil && definition.Token == tok {
+			modifiers |= 1
+		}
+
+		// Positions are encoded relative to the previous token
+		r := d.rangeOf(tok)
+		start := r.Start
+		deltaLine := start.Line - previous.Line
+		deltaStart := start.Character
+		if deltaLine == 0 {
+			deltaStart -= previous.Character
+		}
+		data = append(data, deltaLine, deltaStart, r.End.Character-start.Character, tokenType, modifiers)
+		previous = start
+	}
+	return SemanticTokens{Data: data}
+}
+
+// Index into semanticTokenTypes, or -1 for tokens that are not highlighted
+func semanticTokenType(t token.TokenType) int {
+	switch t {
+	case token.IDENT:
+		return 1
+	case token.INT:
+		return 2
+	case token.ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK,
+		token.SLASH, token.EQ, token.NOT_EQ, token.LT, token.GT:
+		return 3
+	}
+	if token.IsKeyword(t) {
+		return 0
+	}
+	return -1
+}
+
+/**
+ * Helpers
+ */
+
+func positionOf(tok token.Token) position {
+	return position{line: tok.Line, column: tok.Column}
+}
+
+// Tokens never span lines
+func (d *document) rangeOf(tok token.Token) Range {
+	return Range{
+		Start: d.positionAt(tok.Line, tok.Column),
+		End:   d.positionAt(tok.Line, tok.Column+len(tok.Literal)),
+	}
+}
+
+// spanAt returns the range of length bytes from a position, which
+// covers at least one character so that editors can underline it
+func (d *document) spanAt(line int, column int, length int) Range {
+	if length < 1 {
+		length = 1
+	}
+	return Range{
+		Start: d.positionAt(line, column),
+		End:   d.positionAt(line, column+length),
+	}
+}
+
+// positionAt converts a line and byte column (both starting at 1) to an
+// LSP position, whose character is counted in UTF-16 code units
+func (d *document) positionAt(line int, column int) Position {
+	if line < 1 || line > len(d.lines) {
+		return Position{Line: line - 1, Character: column - 1}
+	}
+	// Clients treat characters past the end of the line as its end
+	text := d.lines[line-1]
+	if column-1 > len(text) {
+		return Position{Line: line - 1, Character: utf16Length(text) + column - 1 - len(text)}
+	}
+	return Position{Line: line - 1, Character: utf16Length(text[:column-1])}
+}
+
+func utf16Length(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+func (d *document) location(ident *ast.Identifier) Location {
+	return Location{URI: d.uri, Range: d.rangeOf(ident.Token)}
+}
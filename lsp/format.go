@@ -0,0 +1,96 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/token"
+)
+
+// format reindents the input by nesting depth, using indent for
+// each level, strips trailing whitespace and collapses runs of blank
+// lines. Only whitespace is changed, so comments and code the parser
+// cannot handle yet survive.
+func format(input string, indent string) string {
+	lines := strings.Split(input, "\n")
+
+	// Net change in nesting for each line, and whether
+	// the line starts by closing a block
+	deltas := make([]int, len(lines)+1)
+	closes := make([]bool, len(lines)+1)
+	seen := make([]bool, len(lines)+1)
+
+	for tok := range lexer.Tokens(input) {
+		line := tok.Line - 1
+		switch tok.Type {
+		case token.LBRACE, token.LPAREN:
+			deltas[line]++
+		case token.RBRACE, token.RPAREN:
+			deltas[line]--
+			if !seen[line] {
+				closes[line] = true
+			}
+		}
+		seen[line] = true
+	}
+
+	var out []string
+	depth := 0
+	blank := false
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+
+		level := depth
+		if closes[i] {
+			level--
+		}
+		if level < 0 {
+			level = 0
+		}
+		out = append(out, strings.Repeat(indent, level)+line)
+
+		depth += deltas[i]
+		if depth < 0 {
+			depth = 0
+		}
+	}
+
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// indent returns the text for one level of indentation
+func (o FormattingOptions) indent() string {
+	if !o.InsertSpaces {
+		return "\t"
+	}
+	if o.TabSize < 1 {
+		return "    "
+	}
+	return strings.Repeat(" ", o.TabSize)
+}
+
+// formatEdits returns the edits turning the document into its formatted form
+func (d *document) formatEdits(indent string) []TextEdit {
+	formatted := format(d.text, indent)
+	if formatted == d.text {
+		return []TextEdit{}
+	}
+
+	last := d.lines[len(d.lines)-1]
+	end := Position{Line: len(d.lines) - 1, Character: utf16Length(last)}
+	return []TextEdit{{
+		Range:   Range{Start: Position{}, End: end},
+		NewText: formatted,
+	}}
+}
@@ -0,0 +1,257 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// Server speaks the Language Server Protocol over a pair of streams
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	documents map[string]*document
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: make(map[string]*document),
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input
+func (s *Server) Run() error {
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &responseError{Code: PARSE_ERROR, Message: err.Error()})
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(req)
+		// Notifications have no id and never get a response
+		if req.ID != nil {
+			s.reply(req.ID, result, rpcErr)
+		}
+	}
+}
+
+func (s *Server) handle(req request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		d, err := s.document(req.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return d.hover(params.Position), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		d, err := s.document(req.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		if definition := d.definitionAt(params.Position); definition != nil {
+			return d.location(definition), nil
+		}
+		return nil, nil
+	case "textDocument/references":
+		var params ReferenceParams
+		d, err := s.document(req.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		locations := []Location{}
+		if definition := d.definitionAt(params.Position); definition != nil {
+			for _, ref := range d.references[definition] {
+				if ref == definition && !params.Context.IncludeDeclaration {
+					continue
+				}
+				locations = append(locations, d.location(ref))
+			}
+		}
+		return locations, nil
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		d, err := s.document(req.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return d.symbols(), nil
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		d, err := s.document(req.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return d.formatEdits(params.Options.indent()), nil
+	case "textDocument/semanticTokens/full":
+		var params SemanticTokensParams
+		d, err := s.document(req.Params, &params, &params.TextDocument)
+		if err != nil {
+			return nil, err
+		}
+		return d.semanticTokens(), nil
+
+	default:
+		// Unknown notifications are ignored
+		if req.ID != nil {
+			return nil, &responseError{
+				Code:    METHOD_NOT_FOUND,
+				Message: fmt.Sprintf("method not found: %s", req.Method),
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) initialize() InitializeResult {
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           TEXT_DOCUMENT_SYNC_FULL,
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			DocumentSymbolProvider:     true,
+			DocumentFormattingProvider: true,
+			SemanticTokensProvider: SemanticTokensProvider{
+				Legend: SemanticTokensLegend{
+					TokenTypes:     semanticTokenTypes,
+					TokenModifiers: semanticTokenModifiers,
+				},
+				Full: true,
+			},
+		},
+		ServerInfo: ServerInfo{Name: "monkey"},
+	}
+}
+
+// Reanalyze a document and publish its diagnostics
+func (s *Server) update(uri string, text string) {
+	d := newDocument(uri, text)
+	s.documents[uri] = d
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: d.diagnostics(),
+	})
+}
+
+// Decode params and look up the open document they refer to
+func (s *Server) document(raw json.RawMessage, params interface{}, id *TextDocumentIdentifier) (*document, *responseError) {
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, invalidParams(err)
+	}
+	d, ok := s.documents[id.URI]
+	if !ok {
+		return nil, &responseError{
+			Code:    INVALID_PARAMS,
+			Message: fmt.Sprintf("document not open: %s", id.URI),
+		}
+	}
+	return d, nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: INVALID_PARAMS, Message: err.Error()}
+}
+
+/**
+ * Transport
+ */
+
+// Limit on the size of a message, well above any realistic document
+const MAX_MESSAGE_SIZE = 64 << 20
+
+func (s *Server) readMessage() ([]byte, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	value := header.Get("Content-Length")
+	if value == "" {
+		return nil, fmt.Errorf("missing Content-Length")
+	}
+	length, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %v", err)
+	}
+	if length < 0 || length > MAX_MESSAGE_SIZE {
+		return nil, fmt.Errorf("invalid Content-Length: %d", length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) writeMessage(message interface{}) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rpcErr *responseError) {
+	resp := response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			panic(err)
+		}
+		resp.Result = raw
+	}
+	s.writeMessage(resp)
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.writeMessage(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
@@ -0,0 +1,247 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"strconv"
+	"testing"
+)
+
+const testURI = "file:///test.mk"
+
+const testSource = "let x = 5;\nlet y = x;\n  y;\nz;\n"
+
+// Run the server over a sequence of messages and return its output
+func run(t *testing.T, messages ...string) []map[string]interface{} {
+	var in bytes.Buffer
+	for _, message := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(message), message)
+	}
+	var out bytes.Buffer
+	if err := NewServer(&in, &out).Run(); err != nil {
+		t.Fatalf("Run() returned an error: %v", err)
+	}
+
+	var responses []map[string]interface{}
+	r := bufio.NewReader(&out)
+	for r.Buffered() > 0 || out.Len() > 0 {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err != nil {
+			t.Fatalf("invalid header: %v", err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := r.Read(body); err != nil {
+			t.Fatalf("invalid body: %v", err)
+		}
+
+		var message map[string]interface{}
+		if err := json.Unmarshal(body, &message); err != nil {
+			t.Fatalf("invalid JSON %q: %v", body, err)
+		}
+		responses = append(responses, message)
+	}
+	return responses
+}
+
+func open(text string) string {
+	params, _ := json.Marshal(DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, Text: text},
+	})
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":%s}`, params)
+}
+
+func call(id int, method string, line int, character int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":{`+
+		`"textDocument":{"uri":%q},"position":{"line":%d,"character":%d},`+
+		`"context":{"includeDeclaration":true}}}`,
+		id, method, testURI, line, character)
+}
+
+func toJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func TestInitialize(t *testing.T) {
+	responses := run(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+	capabilities := responses[0]["result"].(map[string]interface{})["capabilities"]
+	if capabilities.(map[string]interface{})["hoverProvider"] != true {
+		t.Errorf("hover should be supported, got %v", capabilities)
+	}
+}
+
+func TestInvalidContentLength(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"Content-Length: -1", "invalid Content-Length: -1"},
+		{"Content-Length: 99999999999", "invalid Content-Length: 99999999999"},
+		{"Content-Length: x", `invalid Content-Length: strconv.Atoi: parsing "x": invalid syntax`},
+		{"Content-Type: text/plain", "missing Content-Length"},
+	}
+
+	for _, tt := range tests {
+		in := bytes.NewBufferString(tt.header + "\r\n\r\n{}")
+		err := NewServer(in, &bytes.Buffer{}).Run()
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%q: expected error %q, got %v", tt.header, tt.expected, err)
+		}
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let x;\nlet y = 5;",
+			`[{"message":"expected next token to be =, got ;",` +
+				`"range":{"end":{"character":6,"line":0},"start":{"character":5,"line":0}},` +
+				`"severity":1,"source":"monkey"}]`,
+		},
+		// Missing tokens at the end of the input are underlined as one character
+		{
+			"let",
+			`[{"message":"expected next token to be IDENT, got EOF",` +
+				`"range":{"end":{"character":4,"line":0},"start":{"character":3,"line":0}},` +
+				`"severity":1,"source":"monkey"}]`,
+		},
+		{
+			"let abc = 5;",
+			`[{"code":"unused","message":"abc is declared but never used",` +
+				`"range":{"end":{"character":7,"line":0},"start":{"character":4,"line":0}},` +
+				`"severity":2,"source":"monkey vet"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		responses := run(t, open(tt.input))
+		if len(responses) != 1 {
+			t.Fatalf("expected 1 notification, got %d", len(responses))
+		}
+
+		params := responses[0]["params"].(map[string]interface{})
+		if actual := toJSON(params["diagnostics"]); actual != tt.expected {
+			t.Errorf("wrong diagnostics for %q.\nexpected=%s\ngot=%s", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestLanguageFeatures(t *testing.T) {
+	tests := []struct {
+		request  string
+		expected string
+	}{
+		{
+			call(1, "textDocument/definition", 2, 2),
+			`{"range":{"end":{"character":5,"line":1},"start":{"character":4,"line":1}},"uri":"file:///test.mk"}`,
+		},
+		{
+			call(2, "textDocument/definition", 3, 0),
+			`null`,
+		},
+		{
+			call(3, "textDocument/references", 0, 4),
+			`[{"range":{"end":{"character":5,"line":0},"start":{"character":4,"line":0}},"uri":"file:///test.mk"},` +
+				`{"range":{"end":{"character":9,"line":1},"start":{"character":8,"line":1}},"uri":"file:///test.mk"}]`,
+		},
+		{
+			call(4, "textDocument/hover", 1, 8),
+			`{"contents":{"kind":"markdown","value":"` + "```monkey\\nlet x\\n```\\n" +
+				`Defined on line 1, used 1 time(s)"},"range":{"end":{"character":9,"line":1},"start":{"character":8,"line":1}}}`,
+		},
+		{
+			call(5, "textDocument/documentSymbol", 0, 0),
+			`[{"kind":13,"name":"x","range":{"end":{"character":5,"line":0},"start":{"character":0,"line":0}},` +
+				`"selectionRange":{"end":{"character":5,"line":0},"start":{"character":4,"line":0}}},` +
+				`{"kind":13,"name":"y","range":{"end":{"character":5,"line":1},"start":{"character":0,"line":1}},` +
+				`"selectionRange":{"end":{"character":5,"line":1},"start":{"character":4,"line":1}}}]`,
+		},
+		{
+			call(6, "textDocument/semanticTokens/full", 0, 0),
+			`{"data":[0,0,3,0,0,0,4,1,1,1,0,2,1,3,0,0,2,1,2,0,1,0,3,0,0,0,4,1,1,1,0,2,1,3,0,0,2,1,1,0,1,2,1,1,0,1,0,1,1,0]}`,
+		},
+		{
+			call(7, "textDocument/formatting", 0, 0),
+			`[{"newText":"let x = 5;\nlet y = x;\ny;\nz;\n","range":{"end":{"character":0,"line":4},"start":{"character":0,"line":0}}}]`,
+		},
+		{
+			call(8, "textDocument/unknown", 0, 0),
+			`{"code":-32601,"message":"method not found: textDocument/unknown"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		responses := run(t, open(testSource), tt.request)
+		if len(responses) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(responses))
+		}
+		response := responses[1]
+		actual := response["result"]
+		if response["error"] != nil {
+			actual = response["error"]
+		}
+		if toJSON(actual) != tt.expected {
+			t.Errorf("wrong response to %s.\nexpected=%s\ngot=%s",
+				tt.request, tt.expected, toJSON(actual))
+		}
+	}
+}
+
+// Characters are counted in UTF-16 code units, where the emoji takes
+// two units but four bytes
+func TestUTF16Positions(t *testing.T) {
+	responses := run(t, open("\U0001F600; let x = 5; x;"), call(1, "textDocument/references", 0, 8))
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(responses))
+	}
+	expected := `[{"range":{"end":{"character":9,"line":0},"start":{"character":8,"line":0}},"uri":"file:///test.mk"},` +
+		`{"range":{"end":{"character":16,"line":0},"start":{"character":15,"line":0}},"uri":"file:///test.mk"}]`
+	if actual := toJSON(responses[1]["result"]); actual != expected {
+		t.Errorf("wrong references.\nexpected=%s\ngot=%s", expected, actual)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	input := "\n\nlet f = fn(x) {\nif (x) {\n    x;   \n}\n\n\n// done\n};\n\n"
+	expected := "let f = fn(x) {\n\tif (x) {\n\t\tx;\n\t}\n\n\t// done\n};\n"
+
+	if actual := format(input, "\t"); actual != expected {
+		t.Errorf("wrong format.\nexpected=%q\ngot=%q", expected, actual)
+	}
+}
+
+func TestFormattingOptions(t *testing.T) {
+	tests := []struct {
+		options  string
+		expected string
+	}{
+		{`{"tabSize":2,"insertSpaces":true}`, "{\n  x;\n}\n"},
+		{`{"tabSize":4,"insertSpaces":false}`, "{\n\tx;\n}\n"},
+	}
+
+	for _, tt := range tests {
+		request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"textDocument/formatting",`+
+			`"params":{"textDocument":{"uri":%q},"options":%s}}`, testURI, tt.options)
+		responses := run(t, open("{\nx;\n}\n"), request)
+		if len(responses) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(responses))
+		}
+		edits := responses[1]["result"].([]interface{})
+		if len(edits) != 1 {
+			t.Fatalf("expected 1 edit with options %s, got %v", tt.options, edits)
+		}
+		if actual := edits[0].(map[string]interface{})["newText"]; actual != tt.expected {
+			t.Errorf("wrong format with options %s.\nexpected=%q\ngot=%q", tt.options, tt.expected, actual)
+		}
+	}
+}
@@ -1,14 +1,43 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
 
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/diagnostic"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/lint"
+	"github.com/matt-snider/monkey/lsp"
+	"github.com/matt-snider/monkey/optimize"
+	"github.com/matt-snider/monkey/parser"
 	"github.com/matt-snider/monkey/repl"
+	"github.com/matt-snider/monkey/types"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "vet":
+			os.Exit(vet(os.Args[2:]))
+		case "check":
+			os.Exit(check(os.Args[2:]))
+		case "ast":
+			os.Exit(printAST(os.Args[2:]))
+		case "lsp":
+			if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+			os.Exit(2)
+		}
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -18,3 +47,156 @@ func main() {
 	repl.Run(os.Stdin, os.Stdout)
 	fmt.Println("")
 }
+
+// vet reports lint diagnostics for each file, returning the exit status.
+// Files are read whole since lint:ignore comments are needed as well.
+func vet(files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: monkey vet file.mk...")
+		return 2
+	}
+
+	renderer := diagnostic.NewRenderer(os.Stdout)
+	status := 0
+	for _, file := range files {
+		input, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+
+		p := parser.New(lexer.New(string(input)))
+		program := p.Parse()
+		if len(p.Errors()) != 0 {
+			for _, e := range p.DetailedErrors() {
+				renderer.Render(os.Stdout, string(input), e.ToDiagnostic(file))
+			}
+			status = 1
+			continue
+		}
+
+		for _, d := range lint.Suppress(lint.Lint(program), string(input)) {
+			renderer.Render(os.Stdout, string(input), d.ToDiagnostic(file))
+			status = 1
+		}
+	}
+	return status
+}
+
+// check reports type errors for each file, returning the exit status
+func check(files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: monkey check file.mk...")
+		return 2
+	}
+
+	renderer := diagnostic.NewRenderer(os.Stdout)
+	status := 0
+	for _, file := range files {
+		program, errors, err := parseFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+
+		var diagnostics []diagnostic.Diagnostic
+		for _, e := range errors {
+			diagnostics = append(diagnostics, e.ToDiagnostic(file))
+		}
+		if len(errors) == 0 {
+			for _, e := range types.Check(program) {
+				diagnostics = append(diagnostics, e.ToDiagnostic(file))
+			}
+		}
+
+		if len(diagnostics) != 0 {
+			source := sourceOf(file)
+			for _, d := range diagnostics {
+				renderer.Render(os.Stdout, source, d)
+			}
+			status = 1
+		}
+	}
+	return status
+}
+
+// parseFile parses a file as it is read, or stdin when file is "-"
+func parseFile(file string) (*ast.Program, []parser.Error, error) {
+	in := os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	p := parser.New(lexer.NewReader(in))
+	program := p.Parse()
+	return program, p.DetailedErrors(), nil
+}
+
+// sourceOf reads a file again to show the lines with diagnostics.
+// Stdin has already been consumed, so its lines are not shown.
+func sourceOf(file string) string {
+	if file == "-" {
+		return ""
+	}
+	input, _ := os.ReadFile(file)
+	return string(input)
+}
+
+// printAST prints the syntax tree of a file, returning the exit status
+func printAST(args []string) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	asDOT := flags.Bool("dot", false, "print the tree as a Graphviz graph")
+	optimized := flags.Bool("optimize", false, "print the tree after optimization")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || (*asJSON && *asDOT) {
+		fmt.Fprintln(os.Stderr, "usage: monkey ast [--optimize] [--json | --dot] file.mk")
+		return 2
+	}
+
+	file := flags.Arg(0)
+	program, errors, err := parseFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(errors) != 0 {
+		renderer := diagnostic.NewRenderer(os.Stderr)
+		source := sourceOf(file)
+		for _, e := range errors {
+			renderer.Render(os.Stderr, source, e.ToDiagnostic(file))
+		}
+		return 1
+	}
+	if *optimized {
+		program = optimize.Optimize(program)
+	}
+
+	switch {
+	case *asJSON:
+		data, err := ast.MarshalJSON(program)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(string(data))
+	case *asDOT:
+		err = ast.DOT(program, os.Stdout)
+	default:
+		err = ast.Dump(program, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package optimize
+
+import (
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/token"
+)
+
+// Optimize returns a simplified copy of a program with the same
+// behavior. Statements are shared with the original program.
+//
+// It drops statements after a return and let statements whose binding
+// is never used and whose value has no effect, repeating until nothing
+// changes since removing a binding can leave others unused.
+//
+// The program must have parsed without errors, since the parser
+// drops statements it cannot fully parse.
+func Optimize(program *ast.Program) *ast.Program {
+	statements := removeUnreachable(program.Statements)
+	for {
+		n := len(statements)
+		statements = removeUnusedLets(statements)
+		if len(statements) == n {
+			break
+		}
+	}
+	return &ast.Program{Statements: statements}
+}
+
+// removeUnreachable drops everything after the first return
+func removeUnreachable(statements []ast.Statement) []ast.Statement {
+	for i, statement := range statements {
+		if _, ok := statement.(*ast.ReturnStatement); ok {
+			return statements[:i+1]
+		}
+	}
+	return statements
+}
+
+func removeUnusedLets(statements []ast.Statement) []ast.Statement {
+	// Resolve identifiers to the let statement currently binding them
+	bindings := make(map[string]*ast.LetStatement)
+	used := make(map[*ast.LetStatement]bool)
+	defined := make(map[ast.Expression]bool)
+	use := func(expression ast.Expression) {
+		switch expression := expression.(type) {
+		case *ast.Identifier:
+			if let, ok := bindings[expression.Value]; ok {
+				used[let] = true
+				defined[expression] = true
+			}
+		case *ast.UnparsedExpression:
+			// Any identifier in it may refer to a binding
+			for _, tok := range expression.Tokens {
+				if let, ok := bindings[tok.Literal]; ok && tok.Type == token.IDENT {
+					used[let] = true
+				}
+			}
+		}
+	}
+
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case *ast.LetStatement:
+			use(statement.Value)
+			bindings[statement.Name.Value] = statement
+		case *ast.ReturnStatement:
+			use(statement.Value)
+		case *ast.ExpressionStatement:
+			use(statement.Expression)
+		}
+	}
+
+	var kept []ast.Statement
+	for _, statement := range statements {
+		if let, ok := statement.(*ast.LetStatement); ok && !used[let] && isPure(let.Value, defined) {
+			continue
+		}
+		kept = append(kept, statement)
+	}
+	return kept
+}
+
+// isPure reports whether evaluating expression cannot fail or have
+// side effects. Undefined identifiers are an error when evaluated.
+func isPure(expression ast.Expression, defined map[ast.Expression]bool) bool {
+	switch expression := expression.(type) {
+	case *ast.IntegerLiteral:
+		return true
+	case *ast.Identifier:
+		return defined[expression]
+	}
+	return false
+}
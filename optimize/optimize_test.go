@@ -0,0 +1,78 @@
+package optimize
+
+import (
+	"testing"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/parser"
+	"github.com/matt-snider/monkey/token"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5; x;", "let x = 5;x;"},
+		// Unreachable code
+		{"let x = 5; return x; x; 6;", "let x = 5;return x;"},
+		// Unused bindings, including ones only used by unused bindings
+		{"let a = 1; let b = a; let c = 2; c;", "let c = 2;c;"},
+		// A shadowed binding is unused if nothing refers to it first
+		{"let x = 1; let x = 2; x;", "let x = 2;x;"},
+		{"let x = 1; let y = x; let x = y; x;", "let x = 1;let y = x;let x = y;x;"},
+		// Undefined identifiers fail when evaluated, so they are kept
+		{"let x = y;", "let x = y;"},
+		// Values the parser can't parse yet may have effects and use bindings
+		{"let a = 5 + launch(); let b = a; 1;", "let a = 5 + launch ( );1;"},
+		{"let a = 1; let b = a * 2; b;", "let a = 1;let b = a * 2;b;"},
+		// Expression statements are kept
+		{"5; let x = 1; return;", "5;return ;"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.Parse()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("%q: parser has errors: %v", tt.input, p.Errors())
+		}
+
+		before := program.String()
+		optimized := Optimize(program)
+		if optimized.String() != tt.expected {
+			t.Errorf("%q should optimize to %q, got %q", tt.input, tt.expected, optimized.String())
+		}
+		if program.String() != before {
+			t.Errorf("%q: Optimize() modified the original program", tt.input)
+		}
+	}
+}
+
+// effect is an expression the optimizer knows nothing
+// about, and which could have side effects
+type effect struct {
+	ast.Expression
+}
+
+func (e *effect) TokenLiteral() string { return "launch" }
+func (e *effect) String() string       { return "launch()" }
+
+func TestEffectfulValues(t *testing.T) {
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "a"}, Value: "a"},
+				Value: &effect{},
+			},
+			&ast.ReturnStatement{Token: token.Token{Type: token.RETURN, Literal: "return"}},
+			&ast.ExpressionStatement{Token: token.Token{Type: token.INT, Literal: "1"}},
+		},
+	}
+
+	expected := "let a = launch();return ;"
+	if actual := Optimize(program).String(); actual != expected {
+		t.Errorf("unused bindings with effects should be kept, expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/token"
+)
+
+// Edit replaces the text between two positions of a document.
+// Lines and columns start at 1, like token positions.
+type Edit struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+	Text        string
+}
+
+// Document is a parsed source text that is kept up to date as it is
+// edited, reparsing only the top-level statements affected by an edit.
+//
+// Statements that are not affected are reused, so nodes returned by
+// an earlier call to Program may have their positions updated.
+type Document struct {
+	text string
+	// Offset of the start of each line
+	lines []int
+	units []unit
+}
+
+// unit is one step of parsing a program, i.e. a top-level
+// statement or the errors from failing to parse one
+type unit struct {
+	offset    int
+	statement ast.Statement
+	errors    []Error
+
+	// End of the text the parser looked at for this unit, which
+	// includes the token after the statement
+	lookahead int
+}
+
+func NewDocument(text string) *Document {
+	d := &Document{text: text, lines: lineOffsets(text)}
+	d.units, _ = d.parse(0, nil)
+	return d
+}
+
+func (d *Document) Text() string {
+	return d.text
+}
+
+func (d *Document) Program() *ast.Program {
+	program := &ast.Program{}
+	for _, u := range d.units {
+		if u.statement != nil {
+			program.Statements = append(program.Statements, u.statement)
+		}
+	}
+	return program
+}
+
+func (d *Document) Errors() []Error {
+	var errors []Error
+	for _, u := range d.units {
+		errors = append(errors, u.errors...)
+	}
+	return errors
+}
+
+// Apply updates the document with an edit. Parsing resumes at the
+// first statement whose lookahead reaches the edit, and stops as
+// soon as it reaches the start of a statement after the edit.
+func (d *Document) Apply(edit Edit) error {
+	start, ok := d.offset(edit.StartLine, edit.StartColumn)
+	if !ok {
+		return fmt.Errorf("invalid edit start %d:%d", edit.StartLine, edit.StartColumn)
+	}
+	end, ok := d.offset(edit.EndLine, edit.EndColumn)
+	if !ok || end < start {
+		return fmt.Errorf("invalid edit end %d:%d", edit.EndLine, edit.EndColumn)
+	}
+
+	// Units before first are kept as they are
+	first := 0
+	for first < len(d.units) && d.units[first].lookahead < start {
+		first++
+	}
+	from := 0
+	if first > 0 {
+		from = d.units[first].offset
+	}
+
+	// Units after the edit can be reused once parsing reaches them
+	delta := len(edit.Text) - (end - start)
+	reusable := make(map[int]int)
+	for i := first; i < len(d.units); i++ {
+		if d.units[i].offset >= end {
+			reusable[d.units[i].offset+delta] = i
+		}
+	}
+
+	old := d.units
+	d.text = d.text[:start] + edit.Text + d.text[end:]
+	d.lines = lineOffsets(d.text)
+	reparsed, resume := d.parse(from, func(offset int) (int, bool) {
+		i, ok := reusable[offset]
+		return i, ok
+	})
+
+	units := append([]unit{}, old[:first]...)
+	units = append(units, reparsed...)
+	if resume >= 0 {
+		shift := positionShift(edit)
+		for _, u := range old[resume:] {
+			u.offset += delta
+			u.lookahead += delta
+			if u.statement != nil {
+				ast.Inspect(u.statement, func(node ast.Node) bool {
+					for _, tok := range ast.TokensOf(node) {
+						tok.Line, tok.Column = shift(tok.Line, tok.Column)
+					}
+					return true
+				})
+			}
+			for i := range u.errors {
+				u.errors[i].Line, u.errors[i].Column = shift(u.errors[i].Line, u.errors[i].Column)
+			}
+			units = append(units, u)
+		}
+	}
+	d.units = units
+	return nil
+}
+
+// parse parses the text from offset until the end, or until
+// resume reports that the statement at an offset can be reused
+func (d *Document) parse(offset int, resume func(int) (int, bool)) ([]unit, int) {
+	line, column := d.position(offset)
+	p := New(lexer.NewAt(d.text[offset:], line, column))
+
+	var units []unit
+	for !p.currTokenIs(token.EOF) {
+		start, _ := d.offset(p.currToken.Line, p.currToken.Column)
+		if resume != nil {
+			if i, ok := resume(start); ok {
+				return units, i
+			}
+		}
+
+		errors := len(p.errors)
+		statement := p.parseStatement()
+		lookahead, _ := d.offset(p.peekToken.Line, p.peekToken.Column)
+		units = append(units, unit{
+			offset:    start,
+			statement: statement,
+			errors:    p.errors[errors:len(p.errors):len(p.errors)],
+			lookahead: lookahead + len(p.peekToken.Literal),
+		})
+		p.nextToken()
+	}
+	return units, -1
+}
+
+/**
+ * Positions
+ */
+
+func lineOffsets(text string) []int {
+	lines := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+func (d *Document) offset(line int, column int) (int, bool) {
+	if line < 1 || line > len(d.lines) || column < 1 {
+		return 0, false
+	}
+	lineEnd := len(d.text)
+	if line < len(d.lines) {
+		lineEnd = d.lines[line] - 1
+	}
+	offset := d.lines[line-1] + column - 1
+	return offset, offset <= lineEnd
+}
+
+func (d *Document) position(offset int) (int, int) {
+	line := 1
+	for line < len(d.lines) && d.lines[line] <= offset {
+		line++
+	}
+	return line, offset - d.lines[line-1] + 1
+}
+
+// positionShift returns a function moving a position after the
+// end of the replaced text to where it is once the edit is applied
+func positionShift(edit Edit) func(int, int) (int, int) {
+	endLine := edit.StartLine + strings.Count(edit.Text, "\n")
+	endColumn := edit.StartColumn + len(edit.Text)
+	if i := strings.LastIndex(edit.Text, "\n"); i >= 0 {
+		endColumn = len(edit.Text) - i
+	}
+
+	return func(line int, column int) (int, int) {
+		if line == edit.EndLine {
+			column += endColumn - edit.EndColumn
+		}
+		return line + endLine - edit.EndLine, column
+	}
+}
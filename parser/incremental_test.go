@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/lexer"
+)
+
+func dump(t *testing.T, program *ast.Program) string {
+	var buf bytes.Buffer
+	if err := ast.Dump(program, &buf); err != nil {
+		t.Fatalf("Dump() returned an error: %v", err)
+	}
+	return buf.String()
+}
+
+// Compare a document against a full parse of its text
+func checkDocument(t *testing.T, d *Document, description string) {
+	p := New(lexer.New(d.Text()))
+	program := p.Parse()
+
+	if expected, actual := dump(t, program), dump(t, d.Program()); expected != actual {
+		t.Fatalf("%s: program differs from a full parse of %q.\nexpected=\n%s\ngot=\n%s",
+			description, d.Text(), expected, actual)
+	}
+	expected, actual := fmt.Sprint(p.DetailedErrors()), fmt.Sprint(d.Errors())
+	if expected != actual {
+		t.Fatalf("%s: errors differ from a full parse of %q.\nexpected=%s\ngot=%s",
+			description, d.Text(), expected, actual)
+	}
+}
+
+func TestDocumentEdits(t *testing.T) {
+	tests := []struct {
+		input    string
+		edit     Edit
+		expected string
+	}{
+		// Change a value in the middle
+		{"let a = 1;\nlet b = 2;\nlet c = b;", Edit{2, 9, 2, 10, "x"}, "let a = 1;\nlet b = x;\nlet c = b;"},
+		// Insert lines
+		{"let a = 1;\nlet c = a;", Edit{1, 11, 1, 11, "\nlet b = a;"}, "let a = 1;\nlet b = a;\nlet c = a;"},
+		// Delete across statements
+		{"let a = 1; let b = 2; let c = 3;", Edit{1, 10, 1, 21, ""}, "let a = 1; let c = 3;"},
+		// Remove a semicolon so statements merge
+		{"let a = b; c; d;", Edit{1, 10, 1, 11, ""}, "let a = b c; d;"},
+		// Break a statement
+		{"let a = 1;\nlet b = a;", Edit{2, 1, 2, 4, "le"}, "let a = 1;\nle b = a;"},
+		// Fix it again
+		{"let a = 1;\nle b = a;", Edit{2, 1, 2, 3, "let"}, "let a = 1;\nlet b = a;"},
+		// Prefix the first statement
+		{"a;\nb;", Edit{1, 1, 1, 1, "let x = "}, "let x = a;\nb;"},
+		// Edit at the very end
+		{"a;", Edit{1, 3, 1, 3, "\nreturn a;"}, "a;\nreturn a;"},
+		// Replace everything
+		{"a;\nb;", Edit{1, 1, 2, 3, ""}, ""},
+	}
+
+	for _, tt := range tests {
+		d := NewDocument(tt.input)
+		if err := d.Apply(tt.edit); err != nil {
+			t.Fatalf("Apply(%+v) returned an error: %v", tt.edit, err)
+		}
+		if d.Text() != tt.expected {
+			t.Fatalf("Apply(%+v) to %q should give %q, got %q", tt.edit, tt.input, tt.expected, d.Text())
+		}
+		checkDocument(t, d, fmt.Sprintf("Apply(%+v) to %q", tt.edit, tt.input))
+	}
+}
+
+func TestDocumentReuse(t *testing.T) {
+	d := NewDocument("let a = 1;\nlet b = a;\nlet c = b;\n")
+	before := d.Program().Statements
+
+	if err := d.Apply(Edit{2, 9, 2, 10, "\n  a"}); err != nil {
+		t.Fatalf("Apply() returned an error: %v", err)
+	}
+	after := d.Program().Statements
+	checkDocument(t, d, "reuse")
+
+	if after[0] != before[0] || after[2] != before[2] {
+		t.Errorf("statements outside the edit should be reused")
+	}
+	if after[1] == before[1] {
+		t.Errorf("the edited statement should be reparsed")
+	}
+	if line := after[2].(*ast.LetStatement).Name.Token.Line; line != 4 {
+		t.Errorf("reused statements should be moved to line 4, got %d", line)
+	}
+}
+
+func TestDocumentInvalidEdit(t *testing.T) {
+	d := NewDocument("let a = 1;\n")
+	for _, edit := range []Edit{{0, 1, 1, 1, ""}, {1, 5, 1, 2, ""}, {1, 12, 1, 12, ""}, {3, 1, 3, 1, ""}} {
+		if err := d.Apply(edit); err == nil {
+			t.Errorf("Apply(%+v) should fail", edit)
+		}
+	}
+}
+
+func TestDocumentRandomEdits(t *testing.T) {
+	fragments := []string{"let ", "x", "y", " = ", "5", ";", "\n", " ", "return", "}", "(", "+", "// c\n", ""}
+	r := rand.New(rand.NewSource(1))
+
+	d := NewDocument("let x = 5;\nlet y = x;\nreturn y;\n")
+	for i := 0; i < 2000; i++ {
+		text := d.Text()
+		start := r.Intn(len(text) + 1)
+		end := start + r.Intn(len(text)-start+1)
+		if end-start > 6 {
+			end = start + 6
+		}
+
+		var replacement strings.Builder
+		for n := r.Intn(3); n > 0; n-- {
+			replacement.WriteString(fragments[r.Intn(len(fragments))])
+		}
+
+		startLine, startColumn := d.position(start)
+		endLine, endColumn := d.position(end)
+		edit := Edit{startLine, startColumn, endLine, endColumn, replacement.String()}
+		if err := d.Apply(edit); err != nil {
+			t.Fatalf("Apply(%+v) to %q returned an error: %v", edit, text, err)
+		}
+		checkDocument(t, d, fmt.Sprintf("Apply(%+v) to %q", edit, text))
+
+		// Keep the document from growing without bound
+		if len(d.Text()) > 200 {
+			d = NewDocument("let x = 5;\nlet y = x;\nreturn y;\n")
+		}
+	}
+}
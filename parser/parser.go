@@ -5,13 +5,14 @@ import (
 	"strconv"
 
 	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/diagnostic"
 	"github.com/matt-snider/monkey/lexer"
 	"github.com/matt-snider/monkey/token"
 )
 
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors []Error
 
 	currToken token.Token
 	peekToken token.Token
@@ -20,6 +21,30 @@ type Parser struct {
 	infixParseFns  map[token.TokenType]infixParseFn
 }
 
+// Error is a parse error along with the position of the
+// token that caused it
+type Error struct {
+	Line    int
+	Column  int
+	Length  int
+	Message string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+func (e Error) ToDiagnostic(file string) diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Severity: diagnostic.ERROR,
+		File:     file,
+		Line:     e.Line,
+		Column:   e.Column,
+		Length:   e.Length,
+		Message:  e.Message,
+	}
+}
+
 // Pratt parsing functions
 type (
 	prefixParseFn func() ast.Expression
@@ -79,19 +104,52 @@ func (p *Parser) Parse() *ast.Program {
 		p.nextToken()
 	}
 
+	// A read error ends the input early, which must not go unnoticed
+	if err := p.l.Err(); err != nil {
+		p.addError(p.currToken, fmt.Sprintf("read error: %v", err))
+	}
+
 	return &program
 }
 
 func (p *Parser) Errors() []string {
+	var messages []string
+	for _, e := range p.errors {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+// DetailedErrors returns the parse errors including their positions
+func (p *Parser) DetailedErrors() []Error {
 	return p.errors
 }
 
+func (p *Parser) addError(tok token.Token, msg string) {
+	p.errors = append(p.errors, Error{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Length:  len(tok.Literal),
+		Message: msg,
+	})
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		// Avoid wrapping a nil statement in a non-nil interface
+		if statement := p.parseLetStatement(); statement != nil {
+			return statement
+		}
+		return nil
 	case token.RETURN:
-		return p.parseReturnStatement()
+		if statement := p.parseReturnStatement(); statement != nil {
+			return statement
+		}
+		return nil
+	case token.SEMICOLON:
+		// Empty statement
+		return nil
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -114,12 +172,25 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	}
 }
 
+// Recover from an error by skipping the rest of the statement,
+// so its remaining tokens are not reported again
+func (p *Parser) skipStatement() {
+	for !p.currTokenIs(token.SEMICOLON) && !p.currTokenIs(token.EOF) {
+		p.nextToken()
+	}
+}
+
+func (p *Parser) noPrefixParseFnError(tok token.Token) {
+	error := fmt.Sprintf("no prefix parse function for %s found", tok.Type)
+	p.addError(tok, error)
+}
+
 func (p *Parser) peekError(t token.TokenType) {
 	error := fmt.Sprintf(
 		"expected next token to be %s, got %s",
 		t, p.peekToken.Type,
 	)
-	p.errors = append(p.errors, error)
+	p.addError(p.peekToken, error)
 }
 
 /**
@@ -140,7 +211,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	if err != nil {
 		error := fmt.Sprintf("could not parse int literal %q",
 			p.currToken.Literal)
-		p.errors = append(p.errors, error)
+		p.addError(p.currToken, error)
 		return nil
 	}
 	return &ast.IntegerLiteral{
@@ -164,14 +235,29 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 		Value: p.currToken.Literal,
 	}
 
+	if p.expectPeek(token.COLON) {
+		if !p.expectPeek(token.IDENT) {
+			p.peekError(token.IDENT)
+			return nil
+		}
+		letStatement.Type = &ast.TypeName{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		}
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
 		p.peekError(token.ASSIGN)
 		return nil
 	}
 
-	// Skip over expression until EOL
-	// TODO: letStatement Expression
-	for !p.currTokenIs(token.SEMICOLON) {
+	p.nextToken()
+	letStatement.Value = p.parseValue()
+	if letStatement.Value == nil {
+		p.skipStatement()
+		return nil
+	}
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -184,15 +270,75 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	returnStatement := &ast.ReturnStatement{Token: p.currToken}
 
-	p.nextToken()
+	// A bare return has no value
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.EOF) {
+		p.nextToken()
+		return returnStatement
+	}
 
-	for !p.currTokenIs(token.SEMICOLON) {
+	p.nextToken()
+	returnStatement.Value = p.parseValue()
+	if returnStatement.Value == nil {
+		p.skipStatement()
+		return nil
+	}
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
 	return returnStatement
 }
 
+/**
+ * Values of let and return statements
+ */
+
+// parseValue parses the value of a let or return statement. Values
+// that can't be parsed yet, such as calls and infix expressions, are
+// kept as an UnparsedExpression holding their tokens.
+func (p *Parser) parseValue() ast.Expression {
+	if p.currTokenIs(token.SEMICOLON) || p.currTokenIs(token.EOF) {
+		p.noPrefixParseFnError(p.currToken)
+		return nil
+	}
+	_, ok := p.prefixParseFns[p.currToken.Type]
+	if ok && (p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.EOF)) {
+		return p.parseExpression(LOWEST)
+	}
+	return p.parseUnparsedExpression()
+}
+
+// Collect the tokens up to the next semicolon outside of
+// parentheses and braces, or the end of the input
+func (p *Parser) parseUnparsedExpression() ast.Expression {
+	unparsed := &ast.UnparsedExpression{}
+	var open []token.Token
+	for {
+		unparsed.Tokens = append(unparsed.Tokens, p.currToken)
+		switch p.currToken.Type {
+		case token.LPAREN, token.LBRACE:
+			open = append(open, p.currToken)
+		case token.RPAREN, token.RBRACE:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+
+		if p.peekTokenIs(token.EOF) {
+			if len(open) > 0 {
+				tok := open[len(open)-1]
+				p.addError(tok, fmt.Sprintf("unclosed %s", tok.Literal))
+				return nil
+			}
+			return unparsed
+		}
+		if len(open) == 0 && p.peekTokenIs(token.SEMICOLON) {
+			return unparsed
+		}
+		p.nextToken()
+	}
+}
+
 /**
  * ExpressionStatement
  */
@@ -214,6 +360,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.currToken.Type]
 
 	if prefix == nil {
+		p.noPrefixParseFnError(p.currToken)
 		return nil
 	}
 	leftExp := prefix()
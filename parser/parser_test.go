@@ -1,10 +1,18 @@
 package parser
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/matt-snider/monkey/ast"
 	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/token"
 )
 
 /**
@@ -67,7 +75,13 @@ func TestParsingErroneousLetStatement(t *testing.T) {
 		let
 	`)
 	p := New(l)
-	p.Parse()
+	program := p.Parse()
+
+	for i, statement := range program.Statements {
+		if statement == nil {
+			t.Errorf("Statement %d of an erroneous program should be skipped, got nil", i)
+		}
+	}
 
 	if len(p.Errors()) != 2 {
 		t.Fatalf("Expected %d errors, got %d", 2, len(p.Errors()))
@@ -211,3 +225,294 @@ func TestIntegerLiteralExpression(t *testing.T) {
 		t.Fatalf("literal.TokenLiteral() should be '5', got '%s'", literal.TokenLiteral())
 	}
 }
+
+func TestLetAndReturnValues(t *testing.T) {
+	l := lexer.New(`
+		let x = 5;
+		let y = x;
+		return y;
+	`)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("Parse() should return %d statements, got=%d",
+			3, len(program.Statements))
+	}
+
+	expected := []string{"5", "x", "y"}
+	for i, expectation := range expected {
+		var value ast.Expression
+		switch statement := program.Statements[i].(type) {
+		case *ast.LetStatement:
+			value = statement.Value
+		case *ast.ReturnStatement:
+			value = statement.Value
+		}
+		if value == nil {
+			t.Fatalf("statement %d has no value", i)
+		}
+		if value.String() != expectation {
+			t.Errorf("statement %d value should be %q, got %q",
+				i, expectation, value.String())
+		}
+	}
+}
+
+func TestLetTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedType string
+		expected     string
+	}{
+		{"let x: int = 5;", "int", "let x: int = 5;"},
+		{"let name : string = x;", "string", "let name: string = x;"},
+		{"let y = 5;", "", "let y = 5;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		let := program.Statements[0].(*ast.LetStatement)
+		if tt.expectedType == "" {
+			if let.Type != nil {
+				t.Errorf("%q should have no annotation, got %q", tt.input, let.Type)
+			}
+		} else if let.Type == nil || let.Type.Value != tt.expectedType {
+			t.Errorf("%q should be annotated with %q, got %v", tt.input, tt.expectedType, let.Type)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q should print as %q, got %q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestLetTypeAnnotationErrors(t *testing.T) {
+	p := New(lexer.New("let x: 5;\nlet y: int 5;"))
+	p.Parse()
+
+	expected := []string{
+		"1:8: expected next token to be IDENT, got INT",
+		"2:12: expected next token to be =, got INT",
+	}
+	errors := p.DetailedErrors()
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), errors)
+	}
+	for i, e := range errors {
+		if e.String() != expected[i] {
+			t.Errorf("error %d should be %q, got %q", i, expected[i], e.String())
+		}
+	}
+}
+
+func TestUnparsedValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5;", "let x = 5;"},
+		{"let b = 2 + a; b;", "let b = 2 + a;b;"},
+		{"let x = add(1, 2);\nreturn x - 1", "let x = add ( 1 , 2 );return x - 1;"},
+		// Semicolons inside braces don't end the statement
+		{"let f = fn(x) { let y = x; y };\nf;", "let f = fn ( x ) { let y = x ; y };f;"},
+		{"return -x", "return - x;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("%q should parse as %q, got %q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestMissingValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = ;", []string{"1:9: no prefix parse function for ; found"}},
+		{"let x = 5;\nlet", []string{"2:4: expected next token to be IDENT, got EOF"}},
+		{"let x =", []string{"1:8: no prefix parse function for EOF found"}},
+		{"let x = f(g(1);\nx;", []string{"1:10: unclosed ("}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+
+		errors := p.DetailedErrors()
+		if len(errors) != len(tt.expected) {
+			t.Errorf("%q: expected %d errors, got %v", tt.input, len(tt.expected), errors)
+			continue
+		}
+		for i, expectation := range tt.expected {
+			if errors[i].String() != expectation {
+				t.Errorf("%q: error %d should be %q, got %q",
+					tt.input, i, expectation, errors[i].String())
+			}
+		}
+		for _, statement := range program.Statements {
+			if statement == nil {
+				t.Errorf("%q: erroneous statements should be skipped, got nil", tt.input)
+			}
+		}
+	}
+}
+
+func TestReadError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader("let x = 5;\n"), iotest.ErrReader(errors.New("disk failure")))
+	p := New(lexer.NewReader(r))
+	program := p.Parse()
+
+	if len(program.Statements) != 1 {
+		t.Errorf("statements read before the error should be kept, got %q", program.String())
+	}
+	errors := p.DetailedErrors()
+	if len(errors) != 1 || errors[0].String() != "2:1: read error: disk failure" {
+		t.Errorf("Parse() should report the read error, got %v", errors)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	l := lexer.New("let x = 5;\nlet 5;")
+	p := New(l)
+	p.Parse()
+
+	errors := p.DetailedErrors()
+	if len(errors) != 1 {
+		t.Fatalf("Expected %d errors, got %d", 1, len(errors))
+	}
+	if errors[0].String() != "2:5: expected next token to be IDENT, got INT" {
+		t.Errorf("Unexpected error %q", errors[0].String())
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	l := lexer.New(`
+		let x = 5;
+		let y: int = x;
+		let z = add(x, 1);
+		return;
+		y;
+	`)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	data, err := ast.MarshalJSON(program)
+	if err != nil {
+		t.Fatalf("MarshalJSON() returned an error: %v", err)
+	}
+	node, err := ast.UnmarshalJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(node, program) {
+		t.Errorf("JSON round-trip produced a different tree: %s", data)
+	}
+}
+
+/**
+ * Round-trip
+ */
+
+var roundTripInputs = []struct {
+	input string
+	valid bool
+}{
+	{"", true},
+	{"let x = 5; let y = x; x; y", true},
+	{"let x: int = 5; let y : string = x", true},
+	{"foo bar baz", true},
+	{"return; return 5", true},
+	{"let x = 007; // comment\n;; x", true},
+	{"let x = add(1, 2); x", true},
+	{"let f = fn(x) { x; }; return f(1) + 2", true},
+	{"if (x) { y } else { z }", false},
+	{"let = ; + ~ 007", false},
+}
+
+// Print the tree without positions, which change when reprinting
+func structure(t testing.TB, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := ast.Dump(node, &buf); err != nil {
+		t.Fatalf("Dump() returned an error: %v", err)
+	}
+	return regexp.MustCompile(` \d+:\d+`).ReplaceAllString(buf.String(), "")
+}
+
+// The tokens of a program apart from semicolons, which
+// are added or dropped when printing
+func significantTokens(input string) []string {
+	var tokens []string
+	for tok := range lexer.Tokens(input) {
+		if tok.Type != token.SEMICOLON {
+			tokens = append(tokens, string(tok.Type)+" "+tok.Literal)
+		}
+	}
+	return tokens
+}
+
+// checkRoundTrip checks that printing a program that parses without
+// errors keeps its tokens and parses to the same tree
+func checkRoundTrip(t testing.TB, input string) {
+	p := New(lexer.New(input))
+	program := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("%q does not parse: %v", input, p.Errors())
+	}
+
+	printed := program.String()
+	if expected, actual := significantTokens(input), significantTokens(printed); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("printing %q as %q changed its tokens.\nexpected=%q\ngot=%q",
+			input, printed, expected, actual)
+	}
+
+	p = New(lexer.New(printed))
+	reparsed := p.Parse()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("printed program %q does not parse: %v", printed, p.Errors())
+	}
+
+	if expected, actual := structure(t, program), structure(t, reparsed); expected != actual {
+		t.Fatalf("round-trip of %q through %q changed the tree.\nexpected=\n%s\ngot=\n%s",
+			input, printed, expected, actual)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range roundTripInputs {
+		if tt.valid {
+			checkRoundTrip(t, tt.input)
+			continue
+		}
+		p := New(lexer.New(tt.input))
+		p.Parse()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%q should not parse", tt.input)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, tt := range roundTripInputs {
+		f.Add(tt.input)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		// Only programs without errors can be printed faithfully
+		p := New(lexer.New(input))
+		p.Parse()
+		if len(p.Errors()) != 0 {
+			return
+		}
+		checkRoundTrip(t, input)
+	})
+}
@@ -0,0 +1,176 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/diagnostic"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/parser"
+)
+
+// session holds the state of a REPL, i.e. the bindings made
+// by the let statements entered so far
+type session struct {
+	out      io.Writer
+	renderer *diagnostic.Renderer
+	bindings map[string]*ast.LetStatement
+}
+
+func newSession(out io.Writer) *session {
+	return &session{
+		out:      out,
+		renderer: diagnostic.NewRenderer(out),
+		bindings: make(map[string]*ast.LetStatement),
+	}
+}
+
+// execute runs a meta command, or parses the entry into the session
+func (s *session) execute(entry string) {
+	if !strings.HasPrefix(entry, ":") {
+		if program := s.parse("", entry); program != nil {
+			s.define(program)
+			fmt.Fprintln(s.out, program.String())
+		}
+		return
+	}
+
+	name, arg, _ := strings.Cut(entry[1:], " ")
+	arg = strings.TrimSpace(arg)
+	for _, c := range commands {
+		if c.name == name {
+			c.run(s, arg)
+			return
+		}
+	}
+	fmt.Fprintf(s.out, "unknown command :%s, see :help\n", name)
+}
+
+// parse returns the program, or prints its errors and returns nil.
+// The file name is used in error messages and may be empty.
+func (s *session) parse(file string, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.Parse()
+	if len(p.Errors()) != 0 {
+		s.report(file, input, p.DetailedErrors())
+		return nil
+	}
+	return program
+}
+
+func (s *session) report(file string, source string, errors []parser.Error) {
+	for _, e := range errors {
+		s.renderer.Render(s.out, source, e.ToDiagnostic(file))
+	}
+}
+
+func (s *session) define(program *ast.Program) {
+	for _, statement := range program.Statements {
+		if let, ok := statement.(*ast.LetStatement); ok {
+			s.bindings[let.Name.Value] = let
+		}
+	}
+}
+
+/**
+ * Commands
+ */
+
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *session, arg string)
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"tokens", ":tokens <src>", "print the tokens of src", (*session).tokens},
+		{"ast", ":ast <src>", "print the syntax tree of src", (*session).ast},
+		{"env", ":env", "list the bindings made so far", (*session).env},
+		{"load", ":load <file>", "parse a file into the session", (*session).load},
+		{"reset", ":reset", "clear all bindings", (*session).reset},
+		{"time", ":time <src>", "measure how long parsing src takes", (*session).time},
+		{"help", ":help", "show this help", (*session).help},
+	}
+}
+
+func (s *session) tokens(arg string) {
+	for tok := range lexer.Tokens(arg) {
+		fmt.Fprintf(s.out, "%+v\n", tok)
+	}
+}
+
+func (s *session) ast(arg string) {
+	if program := s.parse("", arg); program != nil {
+		ast.Dump(program, s.out)
+	}
+}
+
+func (s *session) env(arg string) {
+	var names []string
+	for name := range s.bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(s.out, s.bindings[name].String())
+	}
+}
+
+// The file is parsed as it is read, and only read again
+// to show the lines with errors
+func (s *session) load(arg string) {
+	f, err := os.Open(arg)
+	if err != nil {
+		fmt.Fprintf(s.out, "error: %s\n", err)
+		return
+	}
+	defer f.Close()
+
+	p := parser.New(lexer.NewReader(f))
+	program := p.Parse()
+	if len(p.Errors()) != 0 {
+		source, _ := os.ReadFile(arg)
+		s.report(arg, string(source), p.DetailedErrors())
+		return
+	}
+	s.define(program)
+	fmt.Fprintf(s.out, "loaded %d statements from %s\n", len(program.Statements), arg)
+}
+
+func (s *session) reset(arg string) {
+	s.bindings = make(map[string]*ast.LetStatement)
+}
+
+// Parse repeatedly for at least benchmarkTime to get a stable average
+const benchmarkTime = 100 * time.Millisecond
+
+func (s *session) time(arg string) {
+	if s.parse("", arg) == nil {
+		return
+	}
+
+	runs := 0
+	start := time.Now()
+	for time.Since(start) < benchmarkTime {
+		parser.New(lexer.New(arg)).Parse()
+		runs++
+	}
+	elapsed := time.Since(start)
+	fmt.Fprintf(s.out, "%v per run (%d runs)\n", elapsed/time.Duration(runs), runs)
+}
+
+func (s *session) help(arg string) {
+	fmt.Fprintln(s.out, "Entries are parsed and their let bindings kept in the session.")
+	for _, c := range commands {
+		fmt.Fprintf(s.out, "  %-14s %s\n", c.usage, c.help)
+	}
+}
@@ -0,0 +1,215 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Control keys
+const (
+	CTRL_A    = 1
+	CTRL_B    = 2
+	CTRL_C    = 3
+	CTRL_D    = 4
+	CTRL_E    = 5
+	CTRL_F    = 6
+	CTRL_K    = 11
+	CTRL_N    = 14
+	CTRL_P    = 16
+	CTRL_U    = 21
+	ESCAPE    = 27
+	BACKSPACE = 127
+)
+
+// editor reads lines from a terminal in raw mode, supporting cursor
+// movement, deletion and recalling earlier lines from the history
+type editor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+
+	// Terminal to put in raw mode while reading, or -1
+	fd int
+
+	// State of the line being edited
+	prompt string
+	line   []rune
+	cursor int
+}
+
+func newEditor(f *os.File, out io.Writer, h *history) *editor {
+	return &editor{
+		in:      bufio.NewReader(f),
+		out:     out,
+		history: h,
+		fd:      int(f.Fd()),
+	}
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	if e.fd >= 0 {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	e.prompt = prompt
+	e.line = nil
+	e.cursor = 0
+	// Position in the history, where len(history) is the new line
+	index := len(e.history.lines)
+	var pending []rune
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			line := string(e.line)
+			e.history.add(line)
+			return line, nil
+		case CTRL_C:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case CTRL_D:
+			if len(e.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete()
+		case BACKSPACE, '\b':
+			if e.cursor > 0 {
+				e.cursor--
+				e.delete()
+			}
+		case CTRL_A:
+			e.cursor = 0
+		case CTRL_E:
+			e.cursor = len(e.line)
+		case CTRL_B:
+			e.move(-1)
+		case CTRL_F:
+			e.move(1)
+		case CTRL_K:
+			e.line = e.line[:e.cursor]
+		case CTRL_U:
+			e.line = e.line[e.cursor:]
+			e.cursor = 0
+		case CTRL_P:
+			index, pending = e.recall(index-1, index, pending)
+		case CTRL_N:
+			index, pending = e.recall(index+1, index, pending)
+		case ESCAPE:
+			switch e.readEscape() {
+			case 'A':
+				index, pending = e.recall(index-1, index, pending)
+			case 'B':
+				index, pending = e.recall(index+1, index, pending)
+			case 'C':
+				e.move(1)
+			case 'D':
+				e.move(-1)
+			case 'H':
+				e.cursor = 0
+			case 'F':
+				e.cursor = len(e.line)
+			case '3':
+				e.delete()
+			}
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// readEscape reads the rest of an escape sequence such as
+// "\x1b[A", returning its final character. Modifiers such as
+// the ";5" in "\x1b[1;5C" are skipped, and for sequences like
+// "\x1b[3~" that end with a tilde the key number is returned.
+func (e *editor) readEscape() rune {
+	if r, _, err := e.in.ReadRune(); err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+
+	var params []rune
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil || r < 0x20 || r > 0x7e {
+			return 0
+		}
+		// Parameter and intermediate bytes come before the final byte
+		if r < 0x40 {
+			params = append(params, r)
+			continue
+		}
+		if r != '~' {
+			return r
+		}
+		if len(params) == 1 || (len(params) > 1 && params[1] == ';') {
+			return params[0]
+		}
+		return 0
+	}
+}
+
+func (e *editor) insert(r rune) {
+	e.line = append(e.line, 0)
+	copy(e.line[e.cursor+1:], e.line[e.cursor:])
+	e.line[e.cursor] = r
+	e.cursor++
+}
+
+// delete removes the character under the cursor
+func (e *editor) delete() {
+	if e.cursor < len(e.line) {
+		e.line = append(e.line[:e.cursor], e.line[e.cursor+1:]...)
+	}
+}
+
+func (e *editor) move(n int) {
+	e.cursor += n
+	if e.cursor < 0 {
+		e.cursor = 0
+	} else if e.cursor > len(e.line) {
+		e.cursor = len(e.line)
+	}
+}
+
+// recall replaces the line with the history entry at index. The line
+// being typed is kept in pending while browsing the history.
+func (e *editor) recall(index int, current int, pending []rune) (int, []rune) {
+	if index < 0 || index > len(e.history.lines) {
+		return current, pending
+	}
+	if current == len(e.history.lines) {
+		pending = e.line
+	}
+
+	if index == len(e.history.lines) {
+		e.line = pending
+	} else {
+		e.line = []rune(e.history.lines[index])
+	}
+	e.cursor = len(e.line)
+	return index, pending
+}
+
+// refresh redraws the prompt and line and positions the cursor
+func (e *editor) refresh() {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", e.prompt, string(e.line))
+	if n := len(e.line) - e.cursor; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", n)
+	}
+}
@@ -0,0 +1,61 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+)
+
+// Number of lines of history kept in memory
+const maxHistory = 1000
+
+// history of entered lines, persisted by appending to a file
+type history struct {
+	path  string
+	lines []string
+}
+
+// loadHistory reads the history file at path, if any. An empty
+// path gives a history that is not persisted.
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.remember(scanner.Text())
+	}
+	return h
+}
+
+// add records a line, skipping blank lines and repeats
+func (h *history) add(line string) {
+	if line == "" || (len(h.lines) > 0 && h.lines[len(h.lines)-1] == line) {
+		return
+	}
+	h.remember(line)
+
+	if h.path == "" {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(line + "\n")
+}
+
+func (h *history) remember(line string) {
+	h.lines = append(h.lines, line)
+	if len(h.lines) > maxHistory {
+		h.lines = h.lines[len(h.lines)-maxHistory:]
+	}
+}
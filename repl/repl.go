@@ -2,27 +2,123 @@ package repl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
-	"github.com/matt-snider/monkey/lexer"
-	"github.com/matt-snider/monkey/token"
+const (
+	PROMPT              = ">>> "
+	CONTINUATION_PROMPT = "... "
 )
 
+// Returned by line readers when the user cancels the current input
+var errInterrupted = errors.New("interrupted")
+
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
 func Run(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	reader := newLineReader(in, out)
+	session := newSession(out)
 
+	// Lines are collected until they form a complete entry
+	var lines []string
 	for {
-		fmt.Printf(">>> ")
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if len(lines) > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		line, err := reader.readLine(prompt)
+		if err == errInterrupted {
+			lines = nil
+			continue
+		} else if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Printf("%+v\n", tok)
+		lines = append(lines, line)
+		entry := strings.Join(lines, "\n")
+		if incomplete(entry) {
+			continue
+		}
+		lines = nil
+
+		if strings.TrimSpace(entry) != "" {
+			session.execute(entry)
+		}
+	}
+}
+
+// Use the line editor when both reading from and writing to a
+// terminal, since its escape sequences would garble redirected output
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	if f, ok := in.(*os.File); ok && isTerminal(int(f.Fd())) && isTerminalWriter(out) {
+		return newEditor(f, out, loadHistory(historyPath()))
+	}
+	return &scanner{scanner: bufio.NewScanner(in), out: out}
+}
+
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(int(f.Fd()))
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".monkey_history")
+}
+
+// scanner reads plain lines, e.g. when input is piped in
+type scanner struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (s *scanner) readLine(prompt string) (string, error) {
+	fmt.Fprint(s.out, prompt)
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return s.scanner.Text(), nil
+}
+
+// incomplete reports whether input ends inside a string
+// or with unclosed braces, brackets or parentheses
+func incomplete(input string) bool {
+	depth := 0
+	inString := false
+	for i := 0; i < len(input); i++ {
+		ch := input[i]
+		switch {
+		case inString:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == '/' && strings.HasPrefix(input[i:], "//"):
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+		case ch == '{' || ch == '[' || ch == '(':
+			depth++
+		case ch == '}' || ch == ']' || ch == ')':
+			depth--
 		}
 	}
+	return inString || depth > 0
 }
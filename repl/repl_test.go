@@ -0,0 +1,187 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) {\n  x\n}", false},
+		{"add(1,", true},
+		{"[1, 2", true},
+		{`"abc`, true},
+		{`"a\"bc"`, false},
+		{`"{"`, false},
+		{"{ // }", true},
+		{"}", false},
+	}
+
+	for _, tt := range tests {
+		if actual := incomplete(tt.input); actual != tt.expected {
+			t.Errorf("incomplete(%q) should be %t, got %t", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestRunMultiLine(t *testing.T) {
+	var out bytes.Buffer
+	Run(strings.NewReader("let f = fn(x) {\n x\n};\n5\n"), &out)
+
+	output := out.String()
+	if strings.Count(output, PROMPT) != 3 || strings.Count(output, CONTINUATION_PROMPT) != 2 {
+		t.Errorf("wrong prompts in output %q", output)
+	}
+	if !strings.Contains(output, ">>> ... ... let f = fn ( x ) { x };\n") ||
+		!strings.Contains(output, ">>> 5;\n") {
+		t.Errorf("entries were not parsed, got %q", output)
+	}
+}
+
+func newTestEditor(input string, h *history) *editor {
+	return &editor{
+		in:      bufio.NewReader(strings.NewReader(input)),
+		out:     io.Discard,
+		history: h,
+		fd:      -1,
+	}
+}
+
+func TestNewLineReader(t *testing.T) {
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal: %v", err)
+	}
+	defer tty.Close()
+	if !isTerminal(int(tty.Fd())) {
+		t.Skip("line editing is not supported")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	if _, ok := newLineReader(tty, tty).(*editor); !ok {
+		t.Errorf("a terminal should be read with the line editor")
+	}
+	if _, ok := newLineReader(tty, &bytes.Buffer{}).(*scanner); !ok {
+		t.Errorf("redirected output should disable the line editor")
+	}
+}
+
+func TestEditor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"abc\r", "abc"},
+		{"abc\x7f\x7fd\r", "ad"},
+		{"ac\x1b[Db\r", "abc"},
+		{"bc\x01a\x05d\r", "abcd"},
+		{"abcd\x02\x02\x0b\r", "ab"},
+		{"abcd\x1b[D\x1b[D\x1b[3~\r", "abd"},
+		{"ac\x1b[1;5Db\x1b[1;5C\r", "abc"},
+		{"abcd\x1b[D\x1b[D\x1b[3;5~\x1b[15~\r", "abd"},
+		{"abc\x15x\r", "x"},
+		{"héllo\r", "héllo"},
+		// Recall the previous lines, then go back to the new one
+		{"\x1b[A\r", "second"},
+		{"\x10\x10\r", "first"},
+		{"new\x1b[A\x1b[B\r", "new"},
+	}
+
+	for _, tt := range tests {
+		h := &history{lines: []string{"first", "second"}}
+		actual, err := newTestEditor(tt.input, h).readLine(PROMPT)
+		if err != nil {
+			t.Fatalf("readLine(%q) returned an error: %v", tt.input, err)
+		}
+		if actual != tt.expected {
+			t.Errorf("readLine(%q) should return %q, got %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestEditorControl(t *testing.T) {
+	e := newTestEditor("abc\x03\x04", &history{})
+	if _, err := e.readLine(PROMPT); err != errInterrupted {
+		t.Errorf("Ctrl-C should interrupt, got %v", err)
+	}
+	if _, err := e.readLine(PROMPT); err != io.EOF {
+		t.Errorf("Ctrl-D on an empty line should end input, got %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".monkey_history")
+
+	h := loadHistory(path)
+	for _, line := range []string{"let x = 5;", "", "x", "x"} {
+		h.add(line)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("history was not saved: %v", err)
+	}
+	if string(data) != "let x = 5;\nx\n" {
+		t.Errorf("wrong history file %q", data)
+	}
+
+	loaded := loadHistory(path)
+	if strings.Join(loaded.lines, "|") != "let x = 5;|x" {
+		t.Errorf("wrong history loaded %q", loaded.lines)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.mk")
+	if err := os.WriteFile(file, []byte("let a = 1;\nlet b = a;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(t.TempDir(), "broken.mk")
+	if err := os.WriteFile(broken, []byte("let a = 1;\nlet = 2;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{":tokens let x", []string{
+			"{Type:LET Literal:let Line:1 Column:1 Trivia:}\n",
+			"{Type:IDENT Literal:x Line:1 Column:5 Trivia:}\n",
+		}},
+		{":ast x", []string{"Program\n  Statements[0]: ExpressionStatement 1:1\n"}},
+		{":ast let", []string{"1:4: error: expected next token to be IDENT, got EOF\n"}},
+		{"let x = 5\nlet y = x\n:env", []string{"let x = 5;\nlet y = x;\n"}},
+		{"let x = 5\n:reset\nlet y = 6\n:env", []string{">>> let y = 6;\n>>> "}},
+		{":load " + file + "\n:env", []string{
+			"loaded 2 statements from " + file + "\n",
+			"let a = 1;\nlet b = a;\n",
+		}},
+		{":load " + broken, []string{
+			broken + ":2:5: error: expected next token to be IDENT, got =\n  2 | let = 2;\n",
+		}},
+		{":time let x = 5", []string{" per run ("}},
+		{":help", []string{":tokens <src>", ":time <src>"}},
+		{":nope", []string{"unknown command :nope, see :help\n"}},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Run(strings.NewReader(tt.input+"\n"), &out)
+		for _, expectation := range tt.expected {
+			if !strings.Contains(out.String(), expectation) {
+				t.Errorf("output of %q should contain %q, got %q", tt.input, expectation, out.String())
+			}
+		}
+	}
+}
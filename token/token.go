@@ -3,8 +3,17 @@ package token
 type TokenType string
 
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type    TokenType `json:"type"`
+	Literal string    `json:"literal"`
+
+	// Position of the first character of the token in the input,
+	// both starting at 1
+	Line   int `json:"line"`
+	Column int `json:"column"`
+
+	// Whitespace and comments preceding the token,
+	// only kept by lexers in lossless mode
+	Trivia string `json:"trivia,omitempty"`
 }
 
 const (
@@ -30,6 +39,7 @@ const (
 
 	// Delimiters
 	COMMA     = ","
+	COLON     = ":"
 	SEMICOLON = ";"
 
 	LPAREN = "("
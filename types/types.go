@@ -0,0 +1,111 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/matt-snider/monkey/ast"
+	"github.com/matt-snider/monkey/diagnostic"
+	"github.com/matt-snider/monkey/token"
+)
+
+type Type string
+
+const (
+	INT    Type = "int"
+	STRING Type = "string"
+	BOOL   Type = "bool"
+
+	// The type of anything that is not annotated and cannot be
+	// inferred, which is compatible with every type
+	UNKNOWN Type = ""
+)
+
+var known = map[string]Type{
+	"int":    INT,
+	"string": STRING,
+	"bool":   BOOL,
+}
+
+// Error is a type error along with the position of the
+// token that caused it
+type Error struct {
+	Line    int
+	Column  int
+	Length  int
+	Message string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+func (e Error) ToDiagnostic(file string) diagnostic.Diagnostic {
+	return diagnostic.Diagnostic{
+		Severity: diagnostic.ERROR,
+		File:     file,
+		Line:     e.Line,
+		Column:   e.Column,
+		Length:   e.Length,
+		Message:  e.Message,
+	}
+}
+
+// Check verifies the type annotations of a program. Bindings without
+// an annotation take the type of their value when it can be inferred,
+// and are otherwise not checked.
+func Check(program *ast.Program) []Error {
+	c := &checker{bindings: make(map[string]Type)}
+	for _, statement := range program.Statements {
+		c.checkStatement(statement)
+	}
+	return c.errors
+}
+
+type checker struct {
+	bindings map[string]Type
+	errors   []Error
+}
+
+func (c *checker) report(tok token.Token, format string, args ...interface{}) {
+	c.errors = append(c.errors, Error{
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Length:  len(tok.Literal),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) checkStatement(statement ast.Statement) {
+	let, ok := statement.(*ast.LetStatement)
+	if !ok {
+		return
+	}
+
+	valueType := c.typeOf(let.Value)
+	if let.Type == nil {
+		c.bindings[let.Name.Value] = valueType
+		return
+	}
+
+	annotated, ok := known[let.Type.Value]
+	if !ok {
+		c.report(let.Type.Token, "unknown type %s", let.Type.Value)
+		c.bindings[let.Name.Value] = UNKNOWN
+		return
+	}
+	if valueType != UNKNOWN && valueType != annotated {
+		c.report(*ast.TokenOf(let.Value), "cannot use %s (%s) as %s in let %s",
+			let.Value.String(), valueType, annotated, let.Name.Value)
+	}
+	c.bindings[let.Name.Value] = annotated
+}
+
+func (c *checker) typeOf(expression ast.Expression) Type {
+	switch expression := expression.(type) {
+	case *ast.IntegerLiteral:
+		return INT
+	case *ast.Identifier:
+		return c.bindings[expression.Value]
+	}
+	return UNKNOWN
+}
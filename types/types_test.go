@@ -0,0 +1,56 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/matt-snider/monkey/diagnostic"
+	"github.com/matt-snider/monkey/lexer"
+	"github.com/matt-snider/monkey/parser"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		// Unannotated code is not checked
+		{"let x = 5; let y = x; y; z;", nil},
+		{"let x: int = 5; let y: int = x;", nil},
+		{"let x: string = 5;", []string{"1:17: cannot use 5 (int) as string in let x"}},
+		{"let x: int = 5;\nlet y: bool = x;", []string{"2:15: cannot use x (int) as bool in let y"}},
+		// Types are inferred from unannotated values
+		{"let x = 5;\nlet y = x;\nlet z: string = y;", []string{"3:17: cannot use y (int) as string in let z"}},
+		// Undefined identifiers are left to the linter
+		{"let x: string = y;", nil},
+		{"let x: float = 5;\nlet y: int = x;", []string{"1:8: unknown type float"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.Parse()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("%q: parser has errors: %v", tt.input, p.Errors())
+		}
+
+		errors := Check(program)
+		if len(errors) != len(tt.expected) {
+			t.Errorf("%q: expected %d errors, got %v", tt.input, len(tt.expected), errors)
+			continue
+		}
+		for i, expectation := range tt.expected {
+			if errors[i].String() != expectation {
+				t.Errorf("%q: error %d should be %q, got %q",
+					tt.input, i, expectation, errors[i].String())
+			}
+		}
+	}
+}
+
+func TestToDiagnostic(t *testing.T) {
+	e := Error{Line: 1, Column: 17, Length: 1, Message: "cannot use 5 (int) as string in let x"}
+	d := e.ToDiagnostic("test.mk")
+	if d.Severity != diagnostic.ERROR || d.File != "test.mk" || d.Line != 1 ||
+		d.Column != 17 || d.Length != 1 || d.Message != e.Message {
+		t.Errorf("wrong diagnostic %+v", d)
+	}
+}